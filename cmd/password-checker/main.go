@@ -1,15 +1,19 @@
 package main
 
 import (
+	"context"
 	"log/slog"
 	"os"
+	"os/signal"
 
 	"github.com/vectode/password-checker/internal/app"
 	"github.com/vectode/password-checker/internal/cli"
 	"github.com/vectode/password-checker/internal/config"
 	"github.com/vectode/password-checker/internal/password"
+	"github.com/vectode/password-checker/internal/profiles"
 	"github.com/vectode/password-checker/internal/pwned"
 	"github.com/vectode/password-checker/internal/storage"
+	"github.com/vectode/password-checker/internal/wordlist"
 )
 
 func main() {
@@ -21,7 +25,39 @@ func main() {
 
 	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
 
-	evaluator, err := password.NewEvaluator(password.Policy{MinLength: cfg.Password.MinLength})
+	bannedLists := make([]password.BannedList, 0, len(cfg.Password.BannedLists))
+	for _, path := range cfg.Password.BannedLists {
+		list, err := wordlist.Load(path, cfg.Password.BannedListCacheDir)
+		if err != nil {
+			logger.Error("failed to load banned password list", "path", path, "error", err)
+			os.Exit(1)
+		}
+		bannedLists = append(bannedLists, list)
+	}
+
+	var dictionaries []password.Dictionary
+	for _, path := range cfg.Password.Dictionaries {
+		loaded, err := password.LoadDictionaries(path)
+		if err != nil {
+			logger.Error("failed to load dictionary", "path", path, "error", err)
+			os.Exit(1)
+		}
+		dictionaries = append(dictionaries, loaded...)
+	}
+
+	policy, err := app.ResolvePolicy(cfg.Password.Policy, nil, cfg.Password.MinLength)
+	if err != nil {
+		logger.Error("failed to load password policy", "policy", cfg.Password.Policy, "error", err)
+		os.Exit(1)
+	}
+	if len(policy.KeyboardLayouts) == 0 {
+		policy.KeyboardLayouts = cfg.Password.KeyboardLayouts
+	}
+	policy.BannedLists = bannedLists
+	policy.Dictionaries = dictionaries
+	policy.OrganizationTerms = append(append([]string(nil), cfg.Password.OrganizationTerms...), policy.OrganizationTerms...)
+
+	evaluator, err := password.NewEvaluator(policy)
 	if err != nil {
 		logger.Error("failed to create evaluator", "error", err)
 		os.Exit(1)
@@ -61,7 +97,16 @@ func main() {
 		os.Exit(1)
 	}
 
-	service, err := app.NewService(evaluator, generator, breachChecker, passwordStore)
+	siteProfiles, err := profiles.Load(cfg.Storage.SiteProfilesPath)
+	if err != nil {
+		logger.Error("failed to load site profiles", "error", err)
+		os.Exit(1)
+	}
+
+	service, err := app.NewService(evaluator, generator, breachChecker, passwordStore, app.SaveGate{
+		MinStrength:   password.Strength(cfg.SaveGate.MinStrength),
+		BlockBreached: cfg.SaveGate.BlockBreached,
+	}, siteProfiles, cfg.Password.MinLength)
 	if err != nil {
 		logger.Error("failed to create service", "error", err)
 		os.Exit(1)
@@ -73,8 +118,12 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := commandLine.Run(os.Args[1:]); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := commandLine.Run(ctx, os.Args[1:]); err != nil {
 		logger.Error("command execution failed", "error", err)
+		stop()
 		os.Exit(1)
 	}
 }
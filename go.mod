@@ -1,3 +1,9 @@
 module github.com/vectode/password-checker
 
 go 1.21
+
+require (
+	github.com/rivo/uniseg v0.4.7
+	golang.org/x/text v0.22.0
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"context"
+
+	"github.com/vectode/password-checker/internal/password"
+	"github.com/vectode/password-checker/internal/storage"
+)
+
+// lowRecoveryCodeThreshold is the number of unused recovery codes at or below which the audit warns.
+const lowRecoveryCodeThreshold = 2
+
+// EntryAudit captures the audit result for a single vault entry. Checks that do
+// not apply to the entry's type are listed in Skipped and leave their fields zero.
+type EntryAudit struct {
+	Entry            storage.StoredPassword
+	Strength         password.Strength
+	Findings         []password.Finding
+	Breached         bool
+	ReusedBy         []string
+	LowRecoveryCodes bool
+	Skipped          []storage.AuditCheck
+}
+
+// AuditSavedEntries runs the checks applicable to each entry's type across the whole vault.
+func (s *Service) AuditSavedEntries(ctx context.Context) ([]EntryAudit, error) {
+	entries, err := s.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make(map[string][]string, len(entries))
+	for _, entry := range entries {
+		if entry.Kind().Supports(storage.AuditCheckReuse) {
+			secrets[entry.Secret()] = append(secrets[entry.Secret()], entry.Label)
+		}
+	}
+
+	audits := make([]EntryAudit, 0, len(entries))
+	for _, entry := range entries {
+		kind := entry.Kind()
+		audit := EntryAudit{Entry: entry}
+
+		if kind.Supports(storage.AuditCheckStrength) {
+			assessment := s.evaluator.AssessWithContext(entry.Secret(), password.EvaluationContext{Label: entry.Label})
+			audit.Strength, audit.Findings = assessment.Strength, assessment.Findings
+		} else {
+			audit.Skipped = append(audit.Skipped, storage.AuditCheckStrength)
+		}
+
+		if kind.Supports(storage.AuditCheckBreach) {
+			breached, err := s.breach.IsBreached(ctx, entry.Secret())
+			if err != nil {
+				return nil, err
+			}
+			audit.Breached = breached
+		} else {
+			audit.Skipped = append(audit.Skipped, storage.AuditCheckBreach)
+		}
+
+		if kind.Supports(storage.AuditCheckReuse) {
+			for _, label := range secrets[entry.Secret()] {
+				if label != entry.Label {
+					audit.ReusedBy = append(audit.ReusedBy, label)
+				}
+			}
+		} else {
+			audit.Skipped = append(audit.Skipped, storage.AuditCheckReuse)
+		}
+
+		if kind.Supports(storage.AuditCheckRecoveryCodes) {
+			audit.LowRecoveryCodes = entry.RemainingRecoveryCodes() <= lowRecoveryCodeThreshold
+		} else {
+			audit.Skipped = append(audit.Skipped, storage.AuditCheckRecoveryCodes)
+		}
+
+		audits = append(audits, audit)
+	}
+	return audits, nil
+}
@@ -0,0 +1,70 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vectode/password-checker/internal/password"
+	"github.com/vectode/password-checker/internal/storage"
+)
+
+// SaveGate defines the minimum quality a password must have to be saved without forcing.
+type SaveGate struct {
+	MinStrength   password.Strength
+	BlockBreached bool
+}
+
+// SaveOptions controls how a save request treats the save-time gate. Evaluation adds
+// user and service context to the gate's assessment; the entry label is always included.
+type SaveOptions struct {
+	Force      bool
+	Reason     string
+	Evaluation password.EvaluationContext
+}
+
+// SaveRejectedError is returned when the save-time gate refuses a password.
+type SaveRejectedError struct {
+	Label      string
+	Violations []string
+	Assessment PasswordAssessment
+}
+
+func (e *SaveRejectedError) Error() string {
+	return fmt.Sprintf("refusing to save %s: %s (use --force to override)", e.Label, strings.Join(e.Violations, "; "))
+}
+
+// checkSaveGate evaluates the entry's secret and returns the gate violations, if any.
+// Entry types without password semantics are never gated. Blocking breached passwords
+// queries the breach checker even when the policy ignores breaches.
+func (s *Service) checkSaveGate(ctx context.Context, entry storage.StoredPassword, evaluation password.EvaluationContext) ([]string, PasswordAssessment, error) {
+	if !entry.Kind().Supports(storage.AuditCheckStrength) {
+		return nil, PasswordAssessment{}, nil
+	}
+
+	if evaluation.Label == "" {
+		evaluation.Label = entry.Label
+	}
+	assessment, err := s.EvaluatePassword(ctx, entry.Secret(), evaluation)
+	if err != nil {
+		return nil, PasswordAssessment{}, fmt.Errorf("save gate could not evaluate password: %w", err)
+	}
+
+	var violations []string
+	if !assessment.Strength.AtLeast(s.gate.MinStrength) {
+		violations = append(violations, fmt.Sprintf("strength %s is below the required %s", assessment.Strength, s.gate.MinStrength))
+	}
+	if s.gate.BlockBreached {
+		breached := assessment.Breached
+		// A policy that ignores breaches skips the lookup, but cannot switch off the gate.
+		if s.evaluator.Policy().BreachHandling() == password.BreachIgnore {
+			if breached, err = s.breach.IsBreached(ctx, entry.Secret()); err != nil {
+				return nil, assessment, fmt.Errorf("save gate could not check breaches: %w", err)
+			}
+		}
+		if breached {
+			violations = append(violations, "password appears in known data breaches")
+		}
+	}
+	return violations, assessment, nil
+}
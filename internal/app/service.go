@@ -3,8 +3,12 @@ package app
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/vectode/password-checker/internal/password"
+	"github.com/vectode/password-checker/internal/policyfile"
+	"github.com/vectode/password-checker/internal/profiles"
 	"github.com/vectode/password-checker/internal/storage"
 )
 
@@ -16,11 +20,18 @@ type BreachChecker interface {
 // PasswordGenerator represents a secure password generator.
 type PasswordGenerator interface {
 	Generate(bits int) (string, error)
+	GenerateConstrained(bits int, constraints password.Constraints) (password.Generated, error)
+}
+
+// SiteProfiles resolves the site profile that applies to a label or URL.
+type SiteProfiles interface {
+	Match(target string) (profiles.Profile, bool)
 }
 
 // StrengthEvaluator represents password strength evaluation capabilities.
 type StrengthEvaluator interface {
-	Evaluate(password string) (password.Strength, []password.Finding)
+	AssessWithContext(password string, evaluation password.EvaluationContext) password.Assessment
+	Policy() password.Policy
 }
 
 // Service orchestrates password evaluations and password generation.
@@ -29,10 +40,15 @@ type Service struct {
 	generator PasswordGenerator
 	breach    BreachChecker
 	store     storage.PasswordStore
+	gate      SaveGate
+	profiles  SiteProfiles
+	// defaultMinLength overrides the minimum length of the default preset, see ResolvePolicy.
+	defaultMinLength int
 }
 
-// NewService constructs a service instance.
-func NewService(evaluator StrengthEvaluator, generator PasswordGenerator, breach BreachChecker, store storage.PasswordStore) (*Service, error) {
+// NewService constructs a service instance. defaultMinLength is the configured minimum length
+// of the default preset, applied when WithPolicy selects it; zero keeps the preset's own.
+func NewService(evaluator StrengthEvaluator, generator PasswordGenerator, breach BreachChecker, store storage.PasswordStore, gate SaveGate, siteProfiles SiteProfiles, defaultMinLength int) (*Service, error) {
 	if evaluator == nil {
 		return nil, errors.New("evaluator cannot be nil")
 	}
@@ -45,47 +61,303 @@ func NewService(evaluator StrengthEvaluator, generator PasswordGenerator, breach
 	if store == nil {
 		return nil, errors.New("password store cannot be nil")
 	}
+	if siteProfiles == nil {
+		return nil, errors.New("site profiles cannot be nil")
+	}
+	if _, err := password.ParseStrength(string(gate.MinStrength)); err != nil {
+		return nil, fmt.Errorf("invalid save gate: %w", err)
+	}
 	return &Service{
 		evaluator: evaluator,
 		generator: generator,
 		breach:    breach,
 		store:     store,
+		gate:      gate,
+		profiles:  siteProfiles,
+
+		defaultMinLength: defaultMinLength,
 	}, nil
 }
 
+// ResolvePolicy returns the named preset or the policy file at that path. The built-in
+// default preset takes defaultMinLength as its minimum length when it is positive; policy
+// files are never changed, whatever their name.
+func ResolvePolicy(name string, registry *password.RuleRegistry, defaultMinLength int) (password.Policy, error) {
+	policy, err := policyfile.Resolve(name, registry)
+	if err != nil {
+		return password.Policy{}, err
+	}
+	if _, preset := password.Preset(name); preset && policy.Name == password.DefaultPolicyName && defaultMinLength > 0 {
+		policy.MinLength = defaultMinLength
+	}
+	return policy, nil
+}
+
+// minAlternativeLength is the shortest generated alternative worth offering; it also covers
+// the four character classes the generator always includes.
+const minAlternativeLength = 4
+
+// maxAlternativeAttempts bounds how many generated alternatives are checked against the policy.
+const maxAlternativeAttempts = 20
+
 // PasswordAssessment captures the result of evaluating a password.
 type PasswordAssessment struct {
-	Strength password.Strength
-	Findings []password.Finding
-	Breached bool
+	Strength     password.Strength
+	Score        int
+	Findings     []password.Finding
+	Breached     bool
+	GuessesLog10 float64
+	EntropyBits  float64
+	Length       int
+	Classes      []password.CharacterClass
+	// Mode tells whether the password was evaluated as a passphrase of Words words.
+	Mode       password.Mode
+	Words      int
+	CrackTimes []password.CrackTime
+	// Segments explains which parts of the password make it guessable.
+	Segments []password.Segment
+	// Suggestions propose concrete fixes; Alternative is a generated password with the same
+	// length budget, offered when the password is not strong.
+	Suggestions []password.Suggestion
+	Alternative string
+	// PolicyName and PolicyVersion identify the policy the password was assessed against.
+	PolicyName    string
+	PolicyVersion string
 }
 
-// EvaluatePassword checks the strength of the password and whether it has been pwned.
-func (s *Service) EvaluatePassword(ctx context.Context, pwd string) (PasswordAssessment, error) {
-	strength, findings := s.evaluator.Evaluate(pwd)
-	breached, err := s.breach.IsBreached(ctx, pwd)
+// Policy returns the policy the service evaluates passwords against.
+func (s *Service) Policy() password.Policy {
+	return s.evaluator.Policy()
+}
+
+// WithPolicy returns a service that evaluates passwords against the named preset or the
+// policy file at that path. Deployment settings such as banned lists, dictionaries and
+// organisation terms carry over from the current policy.
+func (s *Service) WithPolicy(name string) (*Service, error) {
+	policy, err := ResolvePolicy(name, s.evaluator.Policy().Registry, s.defaultMinLength)
 	if err != nil {
-		return PasswordAssessment{}, err
+		return nil, err
+	}
+	current := s.evaluator.Policy()
+	if len(policy.KeyboardLayouts) == 0 {
+		policy.KeyboardLayouts = current.KeyboardLayouts
+	}
+	policy.OrganizationTerms = append(append([]string(nil), current.OrganizationTerms...), policy.OrganizationTerms...)
+	policy.BannedLists = current.BannedLists
+	policy.Dictionaries = current.Dictionaries
+
+	evaluator, err := password.NewEvaluator(policy)
+	if err != nil {
+		return nil, fmt.Errorf("policy %s: %w", name, err)
+	}
+	derived := *s
+	derived.evaluator = evaluator
+	return &derived, nil
+}
+
+// EvaluatePassword checks the strength of the password and whether it has been pwned.
+// The evaluation context names the user and service so derived passwords are rejected.
+// How a breach affects the result depends on the policy's breach handling.
+func (s *Service) EvaluatePassword(ctx context.Context, pwd string, evaluation password.EvaluationContext) (PasswordAssessment, error) {
+	assessment := s.evaluator.AssessWithContext(pwd, evaluation)
+	policy := s.evaluator.Policy()
+
+	var breached bool
+	handling := policy.BreachHandling()
+	if handling != password.BreachIgnore {
+		var err error
+		breached, err = s.breach.IsBreached(ctx, pwd)
+		if err != nil {
+			return PasswordAssessment{}, err
+		}
+	}
+	if breached && handling == password.BreachReject {
+		assessment.Strength = password.StrengthWeak
+		assessment.Score = password.Score(assessment.GuessesLog10, assessment.Strength, policy)
+		assessment.Findings = append(assessment.Findings, password.Finding{
+			Code:        "breach.found",
+			Message:     "password appears in known data breaches",
+			Severity:    password.SeverityError,
+			Requirement: "breaches",
+		})
+		assessment.Suggestions = append([]password.Suggestion{{
+			Code:    "suggestion.replace_breached",
+			Message: "choose a password you have never used before; breached passwords are tried first",
+		}}, assessment.Suggestions...)
+	}
+
+	var alternative string
+	if assessment.Strength != password.StrengthStrong {
+		var err error
+		if alternative, err = s.alternative(assessment.Length, policy); err != nil {
+			return PasswordAssessment{}, err
+		}
 	}
 
 	return PasswordAssessment{
-		Strength: strength,
-		Findings: findings,
-		Breached: breached,
+		Strength:      assessment.Strength,
+		Score:         assessment.Score,
+		Findings:      assessment.Findings,
+		Breached:      breached,
+		GuessesLog10:  assessment.GuessesLog10,
+		EntropyBits:   assessment.EntropyBits,
+		Length:        assessment.Length,
+		Classes:       assessment.Classes,
+		Mode:          assessment.Mode,
+		Words:         assessment.Words,
+		CrackTimes:    password.EstimateCrackTimes(assessment.Guesses),
+		Segments:      password.Segments(assessment.Matches, assessment.Findings),
+		Suggestions:   assessment.Suggestions,
+		Alternative:   alternative,
+		PolicyName:    policy.Name,
+		PolicyVersion: policy.Version,
 	}, nil
 }
 
+// alternative generates a random password as long as the assessed one, but at least as long
+// as the policy demands, so users can replace a weak password without needing more room.
+// Forbidden characters are left out of the charset, and candidates the policy still rejects,
+// for example through a deny pattern, are discarded; if none passes, no alternative is offered.
+func (s *Service) alternative(length int, policy password.Policy) (string, error) {
+	if length < policy.MinLength {
+		length = policy.MinLength
+	}
+	if policy.MaxLength > 0 && length > policy.MaxLength {
+		length = policy.MaxLength
+	}
+	if length < minAlternativeLength {
+		return "", nil
+	}
+	constraints := password.Constraints{MaxLength: length, ForbiddenCharacters: policy.ForbiddenCharacters}
+	for attempt := 0; attempt < maxAlternativeAttempts; attempt++ {
+		// The generator stops at MaxLength, so asking for more bits than fit yields exactly length characters.
+		generated, err := s.generator.GenerateConstrained(length*8, constraints)
+		if err != nil {
+			return "", fmt.Errorf("generate alternative: %w", err)
+		}
+		if s.acceptedByPolicy(generated.Password) {
+			return generated.Password, nil
+		}
+	}
+	return "", nil
+}
+
+// acceptedByPolicy reports whether the evaluator raises no error-severity finding for pwd.
+func (s *Service) acceptedByPolicy(pwd string) bool {
+	for _, finding := range s.evaluator.AssessWithContext(pwd, password.EvaluationContext{}).Findings {
+		if finding.Severity == password.SeverityError {
+			return false
+		}
+	}
+	return true
+}
+
 // GeneratePassword produces a secure password at the given bit strength.
 func (s *Service) GeneratePassword(bits int) (string, error) {
 	return s.generator.Generate(bits)
 }
 
-// SavePassword persists a password with the provided label.
-func (s *Service) SavePassword(label, password string) (storage.StoredPassword, error) {
-	return s.store.Save(label, password)
+// GeneratedPassword is a generated password with its real entropy and the site profile applied, if any.
+type GeneratedPassword struct {
+	Password    string
+	EntropyBits float64
+	Profile     string
+}
+
+// GeneratePasswordFor produces a password for the label, honouring the matching site profile.
+// A profile whose maximum length is below the policy's minimum length is reported as an error,
+// since no password can satisfy both.
+func (s *Service) GeneratePasswordFor(label string, bits int) (GeneratedPassword, error) {
+	var (
+		constraints password.Constraints
+		profileName string
+	)
+	if profile, ok := s.profiles.Match(label); ok {
+		constraints = profile.Constraints()
+		profileName = profile.Name
+		if minLength := s.evaluator.Policy().MinLength; profile.MaxLength > 0 && profile.MaxLength < minLength {
+			return GeneratedPassword{}, fmt.Errorf("site profile %s allows at most %d characters, but the password policy requires at least %d", profile.Name, profile.MaxLength, minLength)
+		}
+	}
+
+	generated, err := s.generator.GenerateConstrained(bits, constraints)
+	if err != nil {
+		return GeneratedPassword{}, err
+	}
+	return GeneratedPassword{
+		Password:    generated.Password,
+		EntropyBits: generated.EntropyBits,
+		Profile:     profileName,
+	}, nil
+}
+
+// RotatePassword replaces the password of an existing login entry with a freshly generated one.
+func (s *Service) RotatePassword(ctx context.Context, label string, bits int, opts SaveOptions) (storage.StoredPassword, GeneratedPassword, error) {
+	existing, err := s.store.Get(ctx, label)
+	if err != nil {
+		return storage.StoredPassword{}, GeneratedPassword{}, err
+	}
+	if existing.Kind() != storage.EntryTypeLogin {
+		return storage.StoredPassword{}, GeneratedPassword{}, fmt.Errorf("entry %s is not a login and cannot be rotated", existing.Label)
+	}
+
+	generated, err := s.GeneratePasswordFor(existing.Label, bits)
+	if err != nil {
+		return storage.StoredPassword{}, GeneratedPassword{}, err
+	}
+	record, err := s.SavePassword(ctx, existing.Label, generated.Password, opts)
+	if err != nil {
+		return storage.StoredPassword{}, GeneratedPassword{}, err
+	}
+	return record, generated, nil
+}
+
+// SavePassword persists a password with the provided label after passing the save-time gate.
+func (s *Service) SavePassword(ctx context.Context, label, pwd string, opts SaveOptions) (storage.StoredPassword, error) {
+	entry, err := storage.NewLoginEntry(label, pwd)
+	if err != nil {
+		return storage.StoredPassword{}, err
+	}
+	return s.SaveEntry(ctx, entry, opts)
+}
+
+// SaveEntry persists a typed vault entry. Password-like entries must pass the
+// save-time gate unless opts.Force is set, in which case the override is recorded.
+func (s *Service) SaveEntry(ctx context.Context, entry storage.StoredPassword, opts SaveOptions) (storage.StoredPassword, error) {
+	violations, assessment, err := s.checkSaveGate(ctx, entry, opts.Evaluation)
+	if err != nil {
+		if !opts.Force {
+			return storage.StoredPassword{}, err
+		}
+		violations = []string{err.Error()}
+	}
+
+	entry.Override = nil
+	if len(violations) > 0 {
+		if !opts.Force {
+			return storage.StoredPassword{}, &SaveRejectedError{Label: entry.Label, Violations: violations, Assessment: assessment}
+		}
+		entry.Override = &storage.SaveOverride{Reason: strings.TrimSpace(opts.Reason), Violations: violations}
+	}
+	return s.store.SaveEntry(ctx, entry)
+}
+
+// GetEntry retrieves the vault entry stored under the label.
+func (s *Service) GetEntry(ctx context.Context, label string) (storage.StoredPassword, error) {
+	return s.store.Get(ctx, label)
+}
+
+// ConsumeRecoveryCode marks a recovery code of the labelled entry as used.
+func (s *Service) ConsumeRecoveryCode(ctx context.Context, label, code string) (storage.StoredPassword, error) {
+	return s.store.ConsumeRecoveryCode(ctx, label, code)
 }
 
 // ListSavedPasswords retrieves all stored passwords.
-func (s *Service) ListSavedPasswords() ([]storage.StoredPassword, error) {
-	return s.store.List()
+func (s *Service) ListSavedPasswords(ctx context.Context) ([]storage.StoredPassword, error) {
+	return s.store.List(ctx)
+}
+
+// VerifyVault checks the integrity of the password store and optionally repairs safe issues.
+func (s *Service) VerifyVault(ctx context.Context, repair bool) (storage.VerificationReport, error) {
+	return s.store.Verify(ctx, repair)
 }
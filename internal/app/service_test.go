@@ -0,0 +1,156 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vectode/password-checker/internal/password"
+	"github.com/vectode/password-checker/internal/profiles"
+	"github.com/vectode/password-checker/internal/storage"
+)
+
+// fakeBreaches reports the passwords it holds as breached and counts the lookups.
+type fakeBreaches struct {
+	breached map[string]bool
+	lookups  int
+}
+
+func (f *fakeBreaches) IsBreached(_ context.Context, pwd string) (bool, error) {
+	f.lookups++
+	return f.breached[pwd], nil
+}
+
+func newTestService(t *testing.T, policy password.Policy, gate SaveGate, siteProfiles []profiles.Profile, breaches *fakeBreaches) *Service {
+	t.Helper()
+	evaluator, err := password.NewEvaluator(policy)
+	if err != nil {
+		t.Fatalf("evaluator: %v", err)
+	}
+	generator, err := password.NewGenerator(password.GeneratorPolicy{MinLength: 16, BitsPerCharacter: 6, SpecialCharset: "!@#$%^&*"})
+	if err != nil {
+		t.Fatalf("generator: %v", err)
+	}
+	store, err := storage.NewFileStore(filepath.Join(t.TempDir(), "vault.json"))
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+	set, err := profiles.NewSet(siteProfiles)
+	if err != nil {
+		t.Fatalf("profiles: %v", err)
+	}
+	if breaches == nil {
+		breaches = &fakeBreaches{}
+	}
+	service, err := NewService(evaluator, generator, breaches, store, gate, set, 0)
+	if err != nil {
+		t.Fatalf("service: %v", err)
+	}
+	return service
+}
+
+func TestSaveGateChecksBreachesWhenPolicyIgnoresThem(t *testing.T) {
+	const breachedPassword = "vR8#qL2!xT9@mZ4&"
+	breaches := &fakeBreaches{breached: map[string]bool{breachedPassword: true}}
+	policy := password.Policy{MinLength: 8, Breach: password.BreachIgnore}
+	service := newTestService(t, policy, SaveGate{MinStrength: password.StrengthWeak, BlockBreached: true}, nil, breaches)
+
+	assessment, err := service.EvaluatePassword(context.Background(), breachedPassword, password.EvaluationContext{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if assessment.Breached || breaches.lookups != 0 {
+		t.Fatalf("expected the policy to skip the breach lookup, got breached=%v after %d lookups", assessment.Breached, breaches.lookups)
+	}
+
+	_, err = service.SavePassword(context.Background(), "mail", breachedPassword, SaveOptions{})
+	var rejected *SaveRejectedError
+	if !errors.As(err, &rejected) {
+		t.Fatalf("expected the gate to reject the breached password, got %v", err)
+	}
+	if _, err := service.SavePassword(context.Background(), "mail", "kW7!pQ3#zR8@nV5$", SaveOptions{}); err != nil {
+		t.Fatalf("expected an unbreached password to be saved, got %v", err)
+	}
+}
+
+func TestRotateRejectsProfileShorterThanPolicy(t *testing.T) {
+	siteProfiles := []profiles.Profile{{Name: "legacy-bank", Labels: []string{"bank"}, MaxLength: 8}}
+	service := newTestService(t, password.Policy{MinLength: 12}, SaveGate{MinStrength: password.StrengthModerate}, siteProfiles, nil)
+	ctx := context.Background()
+	if _, err := service.SavePassword(ctx, "bank", "kW7!pQ3#zR8@nV5$", SaveOptions{}); err != nil {
+		t.Fatalf("seed entry: %v", err)
+	}
+
+	_, _, err := service.RotatePassword(ctx, "bank", 128, SaveOptions{})
+	if err == nil {
+		t.Fatal("expected rotation to fail for a profile shorter than the policy minimum")
+	}
+	var rejected *SaveRejectedError
+	if errors.As(err, &rejected) {
+		t.Fatalf("expected the conflict to be reported before the save gate, got %v", err)
+	}
+	for _, want := range []string{"legacy-bank", "at most 8", "at least 12"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected the error to mention %q, got %v", want, err)
+		}
+	}
+
+	siteProfiles[0].MaxLength = 16
+	service = newTestService(t, password.Policy{MinLength: 12}, SaveGate{MinStrength: password.StrengthModerate}, siteProfiles, nil)
+	if _, err := service.SavePassword(ctx, "bank", "kW7!pQ3#zR8@nV5$", SaveOptions{}); err != nil {
+		t.Fatalf("seed entry: %v", err)
+	}
+	if _, generated, err := service.RotatePassword(ctx, "bank", 128, SaveOptions{}); err != nil || len(generated.Password) != 16 {
+		t.Fatalf("expected a 16 character rotation, got %q, %v", generated.Password, err)
+	}
+}
+
+func TestDefaultMinLengthAppliesOnlyToTheDefaultPreset(t *testing.T) {
+	policy, err := ResolvePolicy(password.DefaultPolicyName, nil, 20)
+	if err != nil || policy.MinLength != 20 {
+		t.Fatalf("expected the default preset to take the configured length, got %d, %v", policy.MinLength, err)
+	}
+
+	file := filepath.Join(t.TempDir(), "default.yaml")
+	if err := os.WriteFile(file, []byte("name: default\nmin_length: 9\n"), 0o600); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	if policy, err := ResolvePolicy(file, nil, 20); err != nil || policy.MinLength != 9 {
+		t.Fatalf("expected a policy file named default to keep its length, got %d, %v", policy.MinLength, err)
+	}
+
+	base, _ := password.Preset("nist-800-63b")
+	service := newTestService(t, base, SaveGate{MinStrength: password.StrengthWeak}, nil, nil)
+	service.defaultMinLength = 20
+	derived, err := service.WithPolicy(password.DefaultPolicyName)
+	if err != nil || derived.Policy().MinLength != 20 {
+		t.Fatalf("expected --policy default to match the configured default policy, got %d, %v", derived.Policy().MinLength, err)
+	}
+}
+
+func TestAlternativeFollowsThePolicy(t *testing.T) {
+	policy := password.Policy{MinLength: 12, ForbiddenCharacters: "!@#$%^&*", DenyPatterns: []string{`[0-9]{2}`}}
+	service := newTestService(t, policy, SaveGate{MinStrength: password.StrengthWeak}, nil, nil)
+
+	for i := 0; i < 20; i++ {
+		assessment, err := service.EvaluatePassword(context.Background(), "sommer", password.EvaluationContext{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		alternative := assessment.Alternative
+		if len([]rune(alternative)) != 12 {
+			t.Fatalf("expected a 12 character alternative, got %q", alternative)
+		}
+		if strings.ContainsAny(alternative, policy.ForbiddenCharacters) {
+			t.Fatalf("expected the alternative to avoid forbidden characters, got %q", alternative)
+		}
+		for j := 1; j < len(alternative); j++ {
+			if alternative[j-1] >= '0' && alternative[j-1] <= '9' && alternative[j] >= '0' && alternative[j] <= '9' {
+				t.Fatalf("expected the alternative to avoid the denied pattern, got %q", alternative)
+			}
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"os"
 	"strconv"
 	"strings"
@@ -17,6 +18,8 @@ import (
 	"github.com/vectode/password-checker/internal/app"
 	"github.com/vectode/password-checker/internal/config"
 	"github.com/vectode/password-checker/internal/password"
+	"github.com/vectode/password-checker/internal/policyfile"
+	"github.com/vectode/password-checker/internal/storage"
 	"github.com/vectode/password-checker/internal/version"
 )
 
@@ -54,23 +57,34 @@ func New(service *app.Service, cfg config.Config, logger *slog.Logger) (*CLI, er
 	}, nil
 }
 
-// Run executes the CLI using the provided arguments.
-func (c *CLI) Run(args []string) error {
+// Run executes the CLI using the provided arguments. Cancelling ctx aborts pending
+// breach lookups and storage lock waits.
+func (c *CLI) Run(ctx context.Context, args []string) error {
 	if len(args) == 0 {
-		return c.runInteractive(nil)
+		return c.runInteractive(ctx, nil)
 	}
 
 	switch args[0] {
 	case "check":
-		return c.runCheck(args[1:])
+		return c.runCheck(ctx, args[1:])
 	case "generate":
-		return c.runGenerate(args[1:])
+		return c.runGenerate(ctx, args[1:])
 	case "interactive":
-		return c.runInteractive(args[1:])
+		return c.runInteractive(ctx, args[1:])
 	case "save":
-		return c.runSave(args[1:])
+		return c.runSave(ctx, args[1:])
 	case "list":
-		return c.runList(args[1:])
+		return c.runList(ctx, args[1:])
+	case "get":
+		return c.runGet(ctx, args[1:])
+	case "audit":
+		return c.runAudit(ctx, args[1:])
+	case "verify":
+		return c.runVerify(ctx, args[1:])
+	case "rotate":
+		return c.runRotate(ctx, args[1:])
+	case "policy":
+		return c.runPolicy(args[1:])
 	case "--help", "-h":
 		c.printUsage()
 		return nil
@@ -82,15 +96,28 @@ func (c *CLI) Run(args []string) error {
 	}
 }
 
-func (c *CLI) runCheck(args []string) error {
+func (c *CLI) runCheck(ctx context.Context, args []string) error {
 	fs := flag.NewFlagSet("check", flag.ContinueOnError)
 	fs.SetOutput(c.stderr)
 	passwordFlag := fs.String("password", "", "Password to evaluate. If omitted, the password is read from standard input.")
 	jsonOutput := fs.Bool("json", false, "Render the output as JSON")
+	explain := fs.Bool("explain", false, "Show the segments the password was split into and their guess contribution")
+	evaluation := c.evaluationFlags(fs)
+	labelFlag := fs.String("label", "", "Label of the entry the password is meant for")
+	policyFlag := fs.String("policy", "", "Policy preset ("+strings.Join(password.PresetNames(), ", ")+") or policy file to evaluate against")
 
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	evaluation.Label = strings.TrimSpace(*labelFlag)
+
+	service := c.service
+	if name := strings.TrimSpace(*policyFlag); name != "" {
+		var err error
+		if service, err = c.service.WithPolicy(name); err != nil {
+			return err
+		}
+	}
 
 	if fs.NArg() > 0 {
 		return fmt.Errorf("unexpected arguments: %v", fs.Args())
@@ -109,27 +136,37 @@ func (c *CLI) runCheck(args []string) error {
 		return errors.New("no password provided; use --password or pipe a password to stdin")
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), c.cfg.PwnedAPI.Timeout+2*time.Second)
+	evalCtx, cancel := c.requestContext(ctx)
 	defer cancel()
 
-	assessment, err := c.service.EvaluatePassword(ctx, pwd)
+	assessment, err := service.EvaluatePassword(evalCtx, pwd, *evaluation)
 	if err != nil {
 		return err
 	}
 
 	if *jsonOutput {
-		return c.printAssessmentJSON(assessment)
+		return c.printAssessmentJSON(assessment, *explain)
 	}
 
 	c.printAssessmentHuman(assessment)
-	return c.promptToSavePassword(pwd)
+	if *explain {
+		c.printSegments(assessment.Segments)
+	}
+	// Save through the same policy the assessment above was made with.
+	return c.promptToSavePassword(ctx, service, pwd)
 }
 
-func (c *CLI) runSave(args []string) error {
+func (c *CLI) runSave(ctx context.Context, args []string) error {
 	fs := flag.NewFlagSet("save", flag.ContinueOnError)
 	fs.SetOutput(c.stderr)
 	labelFlag := fs.String("label", "", "Label under which the password should be stored")
 	passwordFlag := fs.String("password", "", "Password to store. If omitted, the password is read from standard input.")
+	typeFlag := fs.String("type", string(storage.EntryTypeLogin), "Entry type: login, note, ssh-key, api-token or recovery-codes")
+	fileFlag := fs.String("file", "", "Read the secret (note text, private key, token or recovery codes) from this file")
+	publicKeyFlag := fs.String("public-key", "", "SSH public key in authorized_keys format (required for ssh-key entries)")
+	forceFlag := fs.Bool("force", false, "Save even if the password is weak or breached")
+	reasonFlag := fs.String("reason", "", "Reason recorded on the entry when --force overrides the save gate")
+	evaluation := c.evaluationFlags(fs)
 
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -143,7 +180,19 @@ func (c *CLI) runSave(args []string) error {
 		return errors.New("label cannot be empty")
 	}
 
+	entryType, err := storage.ParseEntryType(*typeFlag)
+	if err != nil {
+		return err
+	}
+
 	pwd := strings.TrimSpace(*passwordFlag)
+	if pwd == "" && *fileFlag != "" {
+		data, err := os.ReadFile(*fileFlag)
+		if err != nil {
+			return fmt.Errorf("failed to read secret file: %w", err)
+		}
+		pwd = strings.TrimSpace(string(data))
+	}
 	if pwd == "" {
 		piped, err := c.readPasswordFromPipe()
 		if err != nil {
@@ -156,16 +205,114 @@ func (c *CLI) runSave(args []string) error {
 		return errors.New("no password provided; use --password or pipe a password to stdin")
 	}
 
-	record, err := c.service.SavePassword(label, pwd)
+	entry, err := storage.NewEntry(entryType, label, pwd, *publicKeyFlag)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+
+	record, err := c.service.SaveEntry(ctx, entry, app.SaveOptions{Force: *forceFlag, Reason: *reasonFlag, Evaluation: *evaluation})
+	if err != nil {
+		return err
+	}
+	if record.Override != nil {
+		fmt.Fprintf(c.stdout, "Warnung: Speicherrichtlinie übergangen (%s).\n", strings.Join(record.Override.Violations, "; "))
+	}
+
+	fmt.Fprintf(c.stdout, "%s '%s' gespeichert (%s).\n", entryTypeName(record.Kind()), record.Label, record.UpdatedAt.Format(time.RFC1123))
+	return nil
+}
+
+func (c *CLI) runGet(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("get", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	labelFlag := fs.String("label", "", "Label of the entry to display")
+	consumeFlag := fs.String("use-code", "", "Mark the given recovery code as used before displaying the entry")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	label := strings.TrimSpace(*labelFlag)
+	if label == "" {
+		return errors.New("label cannot be empty")
+	}
+
+	var (
+		entry storage.StoredPassword
+		err   error
+	)
+	if code := strings.TrimSpace(*consumeFlag); code != "" {
+		entry, err = c.service.ConsumeRecoveryCode(ctx, label, code)
+	} else {
+		entry, err = c.service.GetEntry(ctx, label)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.printEntry(entry)
+	return nil
+}
+
+func (c *CLI) runAudit(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("audit", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+
+	audits, err := c.service.AuditSavedEntries(ctx)
 	if err != nil {
 		return err
 	}
+	if len(audits) == 0 {
+		fmt.Fprintln(c.stdout, "Keine gespeicherten Passwörter vorhanden.")
+		return nil
+	}
 
-	fmt.Fprintf(c.stdout, "Passwort '%s' gespeichert (%s).\n", record.Label, record.UpdatedAt.Format(time.RFC1123))
+	for _, audit := range audits {
+		fmt.Fprintf(c.stdout, "%s (%s):\n", audit.Entry.Label, entryTypeName(audit.Entry.Kind()))
+		issues := 0
+		if audit.Strength != "" {
+			fmt.Fprintf(c.stdout, "  Stärke: %s\n", strings.ToUpper(string(audit.Strength)))
+			for _, finding := range audit.Findings {
+				fmt.Fprintf(c.stdout, "  - [%s] %s\n", strings.ToUpper(string(finding.Severity)), finding.Message)
+				issues++
+			}
+		}
+		if audit.Breached {
+			fmt.Fprintln(c.stdout, "  Warnung: in Datenlecks gefunden.")
+			issues++
+		}
+		if len(audit.ReusedBy) > 0 {
+			fmt.Fprintf(c.stdout, "  Warnung: wiederverwendet in %s.\n", strings.Join(audit.ReusedBy, ", "))
+			issues++
+		}
+		if audit.LowRecoveryCodes {
+			fmt.Fprintf(c.stdout, "  Warnung: nur noch %d unbenutzte Wiederherstellungscodes.\n", audit.Entry.RemainingRecoveryCodes())
+			issues++
+		}
+		if issues == 0 {
+			fmt.Fprintln(c.stdout, "  Keine Auffälligkeiten.")
+		}
+	}
 	return nil
 }
 
-func (c *CLI) runList(args []string) error {
+func (c *CLI) runList(ctx context.Context, args []string) error {
 	fs := flag.NewFlagSet("list", flag.ContinueOnError)
 	fs.SetOutput(c.stderr)
 
@@ -176,13 +323,133 @@ func (c *CLI) runList(args []string) error {
 		return fmt.Errorf("unexpected arguments: %v", fs.Args())
 	}
 
-	return c.printStoredPasswords()
+	return c.printStoredPasswords(ctx)
+}
+
+func (c *CLI) runVerify(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	repairFlag := fs.Bool("repair", false, "Fix permissions, stale locks, temporary files and timestamps where this is safe")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	report, err := c.service.VerifyVault(ctx, *repairFlag)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.stdout, "Tresor: %s (%d Einträge)\n", report.Path, report.Entries)
+	if len(report.Issues) == 0 {
+		fmt.Fprintln(c.stdout, "Keine Integritätsprobleme gefunden.")
+		return nil
+	}
+	for _, issue := range report.Issues {
+		status := "OFFEN"
+		if issue.Repaired {
+			status = "BEHOBEN"
+		}
+		fmt.Fprintf(c.stdout, " - [%s] %s: %s (%s)\n", status, issue.Code, issue.Message, issue.Path)
+	}
+	if !report.Healthy() {
+		return errors.New("vault verification found unresolved issues")
+	}
+	return nil
+}
+
+func (c *CLI) runPolicy(args []string) error {
+	if len(args) == 0 {
+		return errors.New("policy requires a subcommand: validate or show")
+	}
+	switch args[0] {
+	case "validate":
+		return c.runPolicyValidate(args[1:])
+	case "show":
+		return c.runPolicyShow(args[1:])
+	default:
+		return fmt.Errorf("unknown policy subcommand: %s", args[0])
+	}
+}
+
+func (c *CLI) runPolicyValidate(args []string) error {
+	fs := flag.NewFlagSet("policy validate", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("policy validate expects exactly one policy file")
+	}
+
+	policy, err := policyfile.Load(fs.Arg(0), c.service.Policy().Registry)
+	var invalid *policyfile.Error
+	if errors.As(err, &invalid) {
+		for _, issue := range invalid.Issues {
+			fmt.Fprintf(c.stdout, " - %s\n", issue)
+		}
+		return fmt.Errorf("policy file has %d problem(s)", len(invalid.Issues))
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(c.stdout, "Richtlinie %s ist gültig.\n", policy.Name)
+	return nil
 }
 
-func (c *CLI) runGenerate(args []string) error {
+func (c *CLI) runPolicyShow(args []string) error {
+	fs := flag.NewFlagSet("policy show", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	policyFlag := fs.String("policy", "", "Policy preset or policy file to show instead of the configured policy")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	service := c.service
+	if name := strings.TrimSpace(*policyFlag); name != "" {
+		var err error
+		if service, err = c.service.WithPolicy(name); err != nil {
+			return err
+		}
+	}
+
+	// Spell out the defaults so the output is exactly what the evaluator applies.
+	policy := service.Policy()
+	policy.ModerateGuessesLog10, policy.StrongGuessesLog10 = policy.Thresholds()
+	policy.Breach = policy.BreachHandling()
+	policy.PassphraseMinWords, policy.PassphraseWordlistSize = policy.PassphraseSettings()
+	if len(policy.KeyboardLayouts) == 0 {
+		policy.KeyboardLayouts = password.KeyboardLayouts
+	}
+	codes := password.BuiltinRuleCodes()
+	if policy.Registry != nil {
+		codes = append(codes, policy.Registry.Codes()...)
+	}
+	rules := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		enabled, ok := policy.Rules[code]
+		rules[code] = enabled || !ok
+	}
+	policy.Rules = rules
+
+	encoder := json.NewEncoder(c.stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(policy)
+}
+
+func (c *CLI) runGenerate(ctx context.Context, args []string) error {
 	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
 	fs.SetOutput(c.stderr)
 	bitsFlag := fs.Int("bits", c.cfg.Generator.DefaultBits, "Bit strength for the generated password")
+	labelFlag := fs.String("label", "", "Label or URL whose site profile constrains the generated password")
 
 	if err := fs.Parse(args); err != nil {
 		return err
@@ -196,15 +463,72 @@ func (c *CLI) runGenerate(args []string) error {
 		return errors.New("bits must be greater than zero")
 	}
 
-	password, err := c.service.GeneratePassword(bits)
+	label := strings.TrimSpace(*labelFlag)
+	if label == "" {
+		password, err := c.service.GeneratePassword(bits)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(c.stdout, password)
+		return c.promptToSavePassword(ctx, c.service, password)
+	}
+
+	generated, err := c.service.GeneratePasswordFor(label, bits)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(c.stdout, generated.Password)
+	c.printGeneratedEntropy(generated, bits)
+	return c.promptToSavePassword(ctx, c.service, generated.Password)
+}
+
+func (c *CLI) runRotate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("rotate", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	labelFlag := fs.String("label", "", "Label of the login entry whose password should be replaced")
+	bitsFlag := fs.Int("bits", c.cfg.Generator.DefaultBits, "Bit strength for the new password")
+	forceFlag := fs.Bool("force", false, "Save even if the generated password fails the save gate")
+	reasonFlag := fs.String("reason", "", "Reason recorded on the entry when --force overrides the save gate")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	label := strings.TrimSpace(*labelFlag)
+	if label == "" {
+		return errors.New("label cannot be empty")
+	}
+	if *bitsFlag <= 0 {
+		return errors.New("bits must be greater than zero")
+	}
+
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+
+	record, generated, err := c.service.RotatePassword(ctx, label, *bitsFlag, app.SaveOptions{Force: *forceFlag, Reason: *reasonFlag})
 	if err != nil {
 		return err
 	}
-	fmt.Fprintln(c.stdout, password)
-	return c.promptToSavePassword(password)
+	fmt.Fprintln(c.stdout, generated.Password)
+	c.printGeneratedEntropy(generated, *bitsFlag)
+	fmt.Fprintf(c.stdout, "Passwort '%s' rotiert (%s).\n", record.Label, record.UpdatedAt.Format(time.RFC1123))
+	return nil
+}
+
+func (c *CLI) printGeneratedEntropy(generated app.GeneratedPassword, requestedBits int) {
+	if generated.Profile != "" {
+		fmt.Fprintf(c.stdout, "Profil: %s\n", generated.Profile)
+	}
+	fmt.Fprintf(c.stdout, "Entropie: %.1f Bit\n", generated.EntropyBits)
+	if generated.EntropyBits < float64(requestedBits) {
+		fmt.Fprintf(c.stdout, "Warnung: Das Profil erlaubt nur %.1f der angeforderten %d Bit.\n", generated.EntropyBits, requestedBits)
+	}
 }
 
-func (c *CLI) runInteractive(args []string) error {
+func (c *CLI) runInteractive(ctx context.Context, args []string) error {
 	if len(args) > 0 {
 		return fmt.Errorf("interactive command does not accept arguments: %v", args)
 	}
@@ -236,14 +560,14 @@ func (c *CLI) runInteractive(args []string) error {
 			}
 			pwd = strings.TrimSpace(pwd)
 
-			ctx, cancel := context.WithTimeout(context.Background(), c.cfg.PwnedAPI.Timeout+2*time.Second)
-			assessment, err := c.service.EvaluatePassword(ctx, pwd)
+			evalCtx, cancel := c.requestContext(ctx)
+			assessment, err := c.service.EvaluatePassword(evalCtx, pwd, password.EvaluationContext{})
 			cancel()
 			if err != nil {
 				return err
 			}
 			c.printAssessmentHuman(assessment)
-			if err := c.promptToSavePasswordInteractive(reader, pwd); err != nil {
+			if err := c.promptToSavePasswordInteractive(ctx, reader, c.service, pwd); err != nil {
 				return err
 			}
 		case "2":
@@ -272,17 +596,17 @@ func (c *CLI) runInteractive(args []string) error {
 				return err
 			}
 			fmt.Fprintf(c.stdout, "Generiertes Passwort: %s\n", password)
-			if err := c.promptToSavePasswordInteractive(reader, password); err != nil {
+			if err := c.promptToSavePasswordInteractive(ctx, reader, c.service, password); err != nil {
 				return err
 			}
 		case "3":
 			invalidAttempts = 0
-			if err := c.handleManualSave(reader); err != nil {
+			if err := c.handleManualSave(ctx, reader); err != nil {
 				return err
 			}
 		case "4":
 			invalidAttempts = 0
-			if err := c.printStoredPasswords(); err != nil {
+			if err := c.printStoredPasswords(ctx); err != nil {
 				return err
 			}
 		case "5":
@@ -309,30 +633,114 @@ func (c *CLI) printUsage() {
 	fmt.Fprintln(c.stdout, "  check        Evaluate a password for strength and breaches")
 	fmt.Fprintln(c.stdout, "  generate     Generate a secure password")
 	fmt.Fprintln(c.stdout, "  save         Persist a password with a label")
+	fmt.Fprintln(c.stdout, "  rotate       Replace a stored password with a new one that fits its site profile")
 	fmt.Fprintln(c.stdout, "  list         Display stored passwords")
+	fmt.Fprintln(c.stdout, "  get          Show a stored entry with its type-specific details")
+	fmt.Fprintln(c.stdout, "  audit        Run the checks applicable to each stored entry")
+	fmt.Fprintln(c.stdout, "  verify       Check vault integrity (use --repair to fix safe issues)")
+	fmt.Fprintln(c.stdout, "  policy       Validate a policy file or show the effective policy")
 	fmt.Fprintln(c.stdout, "  interactive  Launch the interactive mode")
 	fmt.Fprintln(c.stdout, "  --version    Print the application version")
 	fmt.Fprintln(c.stdout, "  --help       Show this help message")
 }
 
-func (c *CLI) printAssessmentJSON(assessment app.PasswordAssessment) error {
-	payload := struct {
-		Strength string             `json:"strength"`
-		Findings []password.Finding `json:"findings"`
-		Breached bool               `json:"breached"`
-	}{
-		Strength: string(assessment.Strength),
-		Findings: assessment.Findings,
-		Breached: assessment.Breached,
+// assessmentOutput is the JSON rendering of an assessment. Its format is published as
+// docs/assessment.schema.json; keep both in sync.
+type assessmentOutput struct {
+	Strength     string                `json:"strength"`
+	Score        int                   `json:"score"`
+	Findings     []password.Finding    `json:"findings"`
+	Breached     bool                  `json:"breached"`
+	GuessesLog10 float64               `json:"guesses_log10"`
+	EntropyBits  float64               `json:"entropy_bits"`
+	Length       int                   `json:"length"`
+	Classes      []string              `json:"classes"`
+	Mode         string                `json:"mode"`
+	Words        int                   `json:"words,omitempty"`
+	CrackTimes   []password.CrackTime  `json:"crack_times"`
+	Policy       policyOutput          `json:"policy"`
+	Suggestions  []password.Suggestion `json:"suggestions"`
+	Alternative  string                `json:"alternative,omitempty"`
+	// Segments is only rendered with --explain.
+	Segments []password.Segment `json:"segments,omitempty"`
+}
+
+type policyOutput struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+func newAssessmentOutput(assessment app.PasswordAssessment, explain bool) assessmentOutput {
+	classes := make([]string, len(assessment.Classes))
+	for i, class := range assessment.Classes {
+		classes[i] = string(class)
 	}
+	findings := assessment.Findings
+	if findings == nil {
+		findings = []password.Finding{}
+	}
+	suggestions := assessment.Suggestions
+	if suggestions == nil {
+		suggestions = []password.Suggestion{}
+	}
+	var segments []password.Segment
+	if explain {
+		segments = make([]password.Segment, len(assessment.Segments))
+		for i, segment := range assessment.Segments {
+			segment.GuessesLog10 = roundTo(segment.GuessesLog10, 2)
+			segment.Share = roundTo(segment.Share, 2)
+			segments[i] = segment
+		}
+	}
+	return assessmentOutput{
+		Strength:     string(assessment.Strength),
+		Score:        assessment.Score,
+		Findings:     findings,
+		Breached:     assessment.Breached,
+		GuessesLog10: roundTo(assessment.GuessesLog10, 2),
+		EntropyBits:  roundTo(assessment.EntropyBits, 1),
+		Length:       assessment.Length,
+		Classes:      classes,
+		Mode:         string(assessment.Mode),
+		Words:        assessment.Words,
+		CrackTimes:   assessment.CrackTimes,
+		Policy:       policyOutput{Name: assessment.PolicyName, Version: assessment.PolicyVersion},
+		Suggestions:  suggestions,
+		Alternative:  assessment.Alternative,
+		Segments:     segments,
+	}
+}
 
+func (c *CLI) printAssessmentJSON(assessment app.PasswordAssessment, explain bool) error {
 	encoder := json.NewEncoder(c.stdout)
 	encoder.SetIndent("", "  ")
-	return encoder.Encode(payload)
+	return encoder.Encode(newAssessmentOutput(assessment, explain))
 }
 
 func (c *CLI) printAssessmentHuman(assessment app.PasswordAssessment) {
-	fmt.Fprintf(c.stdout, "Stärke: %s\n", strings.ToUpper(string(assessment.Strength)))
+	fmt.Fprintf(c.stdout, "Stärke: %s (%d/100)\n", strings.ToUpper(string(assessment.Strength)), assessment.Score)
+	policy := assessment.PolicyName
+	if assessment.PolicyVersion != "" {
+		policy += " (Version " + assessment.PolicyVersion + ")"
+	}
+	fmt.Fprintf(c.stdout, "Richtlinie: %s\n", policy)
+	classes := make([]string, len(assessment.Classes))
+	for i, class := range assessment.Classes {
+		classes[i] = string(class)
+	}
+	fmt.Fprintf(c.stdout, "Länge: %d Zeichen (Zeichenklassen: %s)\n", assessment.Length, strings.Join(classes, ", "))
+	if assessment.Mode == password.ModePassphrase {
+		fmt.Fprintf(c.stdout, "Bewertet als: Passphrase aus %d Wörtern\n", assessment.Words)
+	} else {
+		fmt.Fprintln(c.stdout, "Bewertet als: Passwort")
+	}
+	fmt.Fprintf(c.stdout, "Geschätzte Rateversuche: 10^%.1f (%.1f Bit)\n", assessment.GuessesLog10, assessment.EntropyBits)
+	if len(assessment.CrackTimes) > 0 {
+		fmt.Fprintln(c.stdout, "Geschätzte Knackdauer:")
+		for _, crackTime := range assessment.CrackTimes {
+			fmt.Fprintf(c.stdout, " - %s: %s\n", scenarioName(crackTime.Scenario), crackTime.Display)
+		}
+	}
 	if len(assessment.Findings) == 0 {
 		fmt.Fprintln(c.stdout, "Keine Richtlinienverletzungen gefunden.")
 	} else {
@@ -346,6 +754,71 @@ func (c *CLI) printAssessmentHuman(assessment app.PasswordAssessment) {
 	} else {
 		fmt.Fprintln(c.stdout, "Keine Treffer in bekannten Datenlecks.")
 	}
+	if len(assessment.Suggestions) > 0 {
+		fmt.Fprintln(c.stdout, "Vorschläge:")
+		for _, suggestion := range assessment.Suggestions {
+			fmt.Fprintf(c.stdout, " - %s\n", suggestion.Message)
+		}
+	}
+	if assessment.Alternative != "" {
+		fmt.Fprintf(c.stdout, "Generierte Alternative (%d Zeichen): %s\n", password.Length(assessment.Alternative), assessment.Alternative)
+	}
+}
+
+// printSegments shows each segment with the rest of the password masked, so users see which
+// part makes the password guessable and which findings it triggered.
+func (c *CLI) printSegments(segments []password.Segment) {
+	if len(segments) == 0 {
+		return
+	}
+	fmt.Fprintln(c.stdout, "Aufschlüsselung:")
+	for _, segment := range segments {
+		fmt.Fprintf(c.stdout, " - %s  %s: %s, 10^%.1f Rateversuche (%.0f %%)\n", segment.Masked, patternName(segment.Pattern), segment.Detail, segment.GuessesLog10, segment.Share*100)
+		if len(segment.Findings) > 0 {
+			fmt.Fprintf(c.stdout, "   Auslöser für: %s\n", strings.Join(segment.Findings, ", "))
+		}
+	}
+}
+
+func patternName(pattern password.Pattern) string {
+	switch pattern {
+	case password.PatternDictionary:
+		return "Wörterbuchwort"
+	case password.PatternSpatial:
+		return "Tastaturmuster"
+	case password.PatternSequence:
+		return "Zeichenfolge"
+	case password.PatternRepeat:
+		return "Wiederholung"
+	case password.PatternDate:
+		return "Datum"
+	case password.PatternBruteforce:
+		return "Zufallsteil"
+	case password.PatternPassphraseWord:
+		return "Passphrasen-Wort"
+	default:
+		return string(pattern)
+	}
+}
+
+func scenarioName(scenario password.AttackScenario) string {
+	switch scenario {
+	case password.ScenarioOnlineThrottled:
+		return "Online, gedrosselt (100/h)"
+	case password.ScenarioOnlineUnthrottled:
+		return "Online, ungedrosselt (10/s)"
+	case password.ScenarioOfflineSlowHash:
+		return "Offline, langsamer Hash wie bcrypt/Argon2 (10^4/s)"
+	case password.ScenarioOfflineFastHash:
+		return "Offline, schneller Hash wie SHA-1/NTLM (10^10/s)"
+	default:
+		return string(scenario)
+	}
+}
+
+func roundTo(value float64, decimals int) float64 {
+	factor := math.Pow(10, float64(decimals))
+	return math.Round(value*factor) / factor
 }
 
 func (c *CLI) readPasswordFromPipe() (string, error) {
@@ -361,14 +834,14 @@ func (c *CLI) readPasswordFromPipe() (string, error) {
 	return strings.TrimSpace(string(data)), nil
 }
 
-func (c *CLI) promptToSavePassword(password string) error {
+func (c *CLI) promptToSavePassword(ctx context.Context, service *app.Service, password string) error {
 	if !c.stdinIsInteractive() {
 		// Non-interactive context; do not prompt.
 		return nil
 	}
 
 	reader := bufio.NewReader(c.stdin)
-	return c.promptToSavePasswordInteractive(reader, password)
+	return c.promptToSavePasswordInteractive(ctx, reader, service, password)
 }
 
 func (c *CLI) stdinIsInteractive() bool {
@@ -400,7 +873,7 @@ func (c *CLI) detectStdinInteractivity() (bool, error) {
 	return (info.Mode() & os.ModeCharDevice) != 0, nil
 }
 
-func (c *CLI) promptToSavePasswordInteractive(reader *bufio.Reader, password string) error {
+func (c *CLI) promptToSavePasswordInteractive(ctx context.Context, reader *bufio.Reader, service *app.Service, password string) error {
 	if password == "" {
 		return nil
 	}
@@ -424,7 +897,34 @@ func (c *CLI) promptToSavePasswordInteractive(reader *bufio.Reader, password str
 		return nil
 	}
 
-	record, err := c.service.SavePassword(label, password)
+	return c.savePasswordInteractive(ctx, reader, service, label, password)
+}
+
+// savePasswordInteractive saves the password through the service, whose policy the save gate
+// applies, and, when the gate refuses it, offers to override the gate after asking for a reason.
+func (c *CLI) savePasswordInteractive(ctx context.Context, reader *bufio.Reader, service *app.Service, label, password string) error {
+	record, err := c.savePassword(ctx, service, label, password, app.SaveOptions{})
+	var rejected *app.SaveRejectedError
+	if errors.As(err, &rejected) {
+		fmt.Fprintln(c.stdout, "Das Passwort erfüllt die Speicherrichtlinie nicht:")
+		for _, violation := range rejected.Violations {
+			fmt.Fprintf(c.stdout, " - %s\n", violation)
+		}
+		force, askErr := c.askYesNo(reader, "Trotzdem speichern? (j/n): ")
+		if askErr != nil {
+			return askErr
+		}
+		if !force {
+			fmt.Fprintln(c.stdout, "Passwort wurde nicht gespeichert.")
+			return nil
+		}
+		fmt.Fprint(c.stdout, "Begründung: ")
+		reason, readErr := reader.ReadString('\n')
+		if readErr != nil {
+			return readErr
+		}
+		record, err = c.savePassword(ctx, service, label, password, app.SaveOptions{Force: true, Reason: strings.TrimSpace(reason)})
+	}
 	if err != nil {
 		return err
 	}
@@ -432,7 +932,28 @@ func (c *CLI) promptToSavePasswordInteractive(reader *bufio.Reader, password str
 	return nil
 }
 
-func (c *CLI) handleManualSave(reader *bufio.Reader) error {
+func (c *CLI) savePassword(ctx context.Context, service *app.Service, label, password string, opts app.SaveOptions) (storage.StoredPassword, error) {
+	saveCtx, cancel := c.requestContext(ctx)
+	defer cancel()
+	return service.SavePassword(saveCtx, label, password, opts)
+}
+
+// evaluationFlags registers the flags describing the user and service a password is for.
+// The label is taken from the command's own --label flag.
+func (c *CLI) evaluationFlags(fs *flag.FlagSet) *password.EvaluationContext {
+	evaluation := &password.EvaluationContext{}
+	fs.StringVar(&evaluation.Username, "username", "", "Username of the account; passwords containing it are rejected")
+	fs.StringVar(&evaluation.Email, "email", "", "E-mail address of the account; passwords containing its parts are rejected")
+	fs.StringVar(&evaluation.URL, "url", "", "URL or host of the service; passwords containing its name are rejected")
+	return evaluation
+}
+
+// requestContext bounds a single breach lookup or storage operation.
+func (c *CLI) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, c.cfg.PwnedAPI.Timeout+2*time.Second)
+}
+
+func (c *CLI) handleManualSave(ctx context.Context, reader *bufio.Reader) error {
 	fmt.Fprint(c.stdout, "Bezeichnung: ")
 	label, err := reader.ReadString('\n')
 	if err != nil {
@@ -455,16 +976,11 @@ func (c *CLI) handleManualSave(reader *bufio.Reader) error {
 		return nil
 	}
 
-	record, err := c.service.SavePassword(label, pwd)
-	if err != nil {
-		return err
-	}
-	fmt.Fprintf(c.stdout, "Passwort gespeichert unter '%s'.\n", record.Label)
-	return nil
+	return c.savePasswordInteractive(ctx, reader, c.service, label, pwd)
 }
 
-func (c *CLI) printStoredPasswords() error {
-	entries, err := c.service.ListSavedPasswords()
+func (c *CLI) printStoredPasswords(ctx context.Context) error {
+	entries, err := c.service.ListSavedPasswords(ctx)
 	if err != nil {
 		return err
 	}
@@ -476,11 +992,74 @@ func (c *CLI) printStoredPasswords() error {
 
 	fmt.Fprintln(c.stdout, "Gespeicherte Passwörter:")
 	for _, entry := range entries {
-		fmt.Fprintf(c.stdout, "- %s: %s (zuletzt aktualisiert %s)\n", entry.Label, entry.Password, entry.UpdatedAt.Format(time.RFC1123))
+		if entry.Kind() == storage.EntryTypeLogin {
+			fmt.Fprintf(c.stdout, "- %s: %s (zuletzt aktualisiert %s)\n", entry.Label, entry.Password, entry.UpdatedAt.Format(time.RFC1123))
+			continue
+		}
+		fmt.Fprintf(c.stdout, "- %s: [%s] (zuletzt aktualisiert %s)\n", entry.Label, entryTypeName(entry.Kind()), entry.UpdatedAt.Format(time.RFC1123))
 	}
 	return nil
 }
 
+func (c *CLI) printEntry(entry storage.StoredPassword) {
+	fmt.Fprintf(c.stdout, "Bezeichnung: %s\n", entry.Label)
+	fmt.Fprintf(c.stdout, "Typ: %s\n", entryTypeName(entry.Kind()))
+	switch entry.Kind() {
+	case storage.EntryTypeSecureNote:
+		fmt.Fprintln(c.stdout, "Notiz:")
+		fmt.Fprintln(c.stdout, entry.Note)
+	case storage.EntryTypeSSHKey:
+		fmt.Fprintf(c.stdout, "Algorithmus: %s\n", entry.SSHKey.Algorithm)
+		fmt.Fprintf(c.stdout, "Fingerabdruck: %s\n", entry.SSHKey.Fingerprint)
+		if entry.SSHKey.Comment != "" {
+			fmt.Fprintf(c.stdout, "Kommentar: %s\n", entry.SSHKey.Comment)
+		}
+		fmt.Fprintf(c.stdout, "Öffentlicher Schlüssel: %s\n", entry.SSHKey.PublicKey)
+		fmt.Fprint(c.stdout, entry.SSHKey.PrivateKey)
+	case storage.EntryTypeAPIToken:
+		provider := entry.APIToken.Provider
+		if provider == "" {
+			provider = "unbekannt"
+		}
+		fmt.Fprintf(c.stdout, "Anbieter: %s\n", provider)
+		fmt.Fprintf(c.stdout, "Token: %s\n", entry.APIToken.Token)
+	case storage.EntryTypeRecoveryCodes:
+		fmt.Fprintf(c.stdout, "Codes: %d verbraucht, %d verfügbar\n", entry.ConsumedRecoveryCodes(), entry.RemainingRecoveryCodes())
+		for _, code := range entry.RecoveryCodes {
+			if code.UsedAt != nil {
+				fmt.Fprintf(c.stdout, "  %s (verwendet %s)\n", code.Code, code.UsedAt.Format(time.RFC1123))
+				continue
+			}
+			fmt.Fprintf(c.stdout, "  %s\n", code.Code)
+		}
+	default:
+		fmt.Fprintf(c.stdout, "Passwort: %s\n", entry.Password)
+	}
+	if entry.Override != nil {
+		fmt.Fprintf(c.stdout, "Richtlinie übergangen: %s\n", strings.Join(entry.Override.Violations, "; "))
+		if entry.Override.Reason != "" {
+			fmt.Fprintf(c.stdout, "Begründung: %s\n", entry.Override.Reason)
+		}
+	}
+	fmt.Fprintf(c.stdout, "Erstellt: %s\n", entry.CreatedAt.Format(time.RFC1123))
+	fmt.Fprintf(c.stdout, "Zuletzt aktualisiert: %s\n", entry.UpdatedAt.Format(time.RFC1123))
+}
+
+func entryTypeName(entryType storage.EntryType) string {
+	switch entryType {
+	case storage.EntryTypeSecureNote:
+		return "Sichere Notiz"
+	case storage.EntryTypeSSHKey:
+		return "SSH-Schlüssel"
+	case storage.EntryTypeAPIToken:
+		return "API-Token"
+	case storage.EntryTypeRecoveryCodes:
+		return "Wiederherstellungscodes"
+	default:
+		return "Passwort"
+	}
+}
+
 func (c *CLI) askYesNo(reader *bufio.Reader, prompt string) (bool, error) {
 	fmt.Fprint(c.stdout, prompt)
 	response, err := reader.ReadString('\n')
@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"encoding/json"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/vectode/password-checker/internal/app"
+	"github.com/vectode/password-checker/internal/password"
+)
+
+// keysOf returns the sorted keys of a JSON object.
+func keysOf(t *testing.T, object map[string]json.RawMessage) []string {
+	t.Helper()
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestAssessmentOutputMatchesPublishedSchema(t *testing.T) {
+	data, err := os.ReadFile("../../docs/assessment.schema.json")
+	if err != nil {
+		t.Fatalf("read schema: %v", err)
+	}
+	var schema struct {
+		Required   []string                   `json:"required"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("decode schema: %v", err)
+	}
+
+	encoded, err := json.Marshal(newAssessmentOutput(app.PasswordAssessment{
+		Strength:      password.StrengthModerate,
+		Score:         55,
+		Classes:       []password.CharacterClass{password.ClassLower, password.ClassDigit},
+		Mode:          password.ModePassword,
+		CrackTimes:    password.EstimateCrackTimes(1e9),
+		PolicyName:    "nist-800-63b",
+		PolicyVersion: "SP 800-63B-4",
+	}, false))
+	if err != nil {
+		t.Fatalf("encode output: %v", err)
+	}
+	var output map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &output); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+
+	got := keysOf(t, output)
+	want := append([]string(nil), schema.Required...)
+	sort.Strings(want)
+	if len(got) != len(want) {
+		t.Fatalf("output keys %v do not match schema %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("output keys %v do not match schema %v", got, want)
+		}
+		if _, ok := schema.Properties[got[i]]; !ok {
+			t.Fatalf("schema does not describe %q", got[i])
+		}
+	}
+	if string(output["findings"]) != "[]" || string(output["suggestions"]) != "[]" {
+		t.Fatalf("expected empty findings and suggestions arrays, got %s and %s", output["findings"], output["suggestions"])
+	}
+}
+
+func TestAssessmentOutputExplainsSegments(t *testing.T) {
+	assessment := app.PasswordAssessment{
+		Strength: password.StrengthWeak,
+		Segments: []password.Segment{{Pattern: password.PatternDate, Start: 1, End: 4, Masked: "2024", GuessesLog10: 1.30103, Share: 1}},
+	}
+
+	plain, err := json.Marshal(newAssessmentOutput(assessment, false))
+	if err != nil {
+		t.Fatalf("encode output: %v", err)
+	}
+	var output map[string]json.RawMessage
+	if err := json.Unmarshal(plain, &output); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if _, ok := output["segments"]; ok {
+		t.Fatal("expected segments to be omitted without --explain")
+	}
+
+	explained, err := json.Marshal(newAssessmentOutput(assessment, true))
+	if err != nil {
+		t.Fatalf("encode output: %v", err)
+	}
+	var withSegments struct {
+		Segments []map[string]any `json:"segments"`
+	}
+	if err := json.Unmarshal(explained, &withSegments); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if len(withSegments.Segments) != 1 || withSegments.Segments[0]["guesses_log10"] != 1.3 || withSegments.Segments[0]["pattern"] != "date" {
+		t.Fatalf("expected the rounded date segment, got %s", explained)
+	}
+}
+
+func TestAssessmentOutputEncodesVeryLongPasswords(t *testing.T) {
+	evaluator, err := password.NewEvaluator(password.Policy{MinLength: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// A repeated pattern would be cheap to guess; only random characters reach the guess cap.
+	const charset = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789!?#%&*+="
+	random := rand.New(rand.NewSource(1))
+	var long strings.Builder
+	for long.Len() < 400 {
+		long.WriteByte(charset[random.Intn(len(charset))])
+	}
+	assessed := evaluator.Assess(long.String())
+	assessment := app.PasswordAssessment{
+		Strength:     assessed.Strength,
+		Score:        assessed.Score,
+		Findings:     assessed.Findings,
+		GuessesLog10: assessed.GuessesLog10,
+		EntropyBits:  assessed.EntropyBits,
+		Length:       assessed.Length,
+		Classes:      assessed.Classes,
+		Mode:         assessed.Mode,
+		CrackTimes:   password.EstimateCrackTimes(assessed.Guesses),
+		Segments:     password.Segments(assessed.Matches, assessed.Findings),
+		Suggestions:  assessed.Suggestions,
+	}
+	if _, err := json.Marshal(newAssessmentOutput(assessment, true)); err != nil {
+		t.Fatalf("expected a 400 character password to encode, got %v", err)
+	}
+}
+
+func TestHumanOutputNamesTheAlternativeLength(t *testing.T) {
+	var out strings.Builder
+	c := &CLI{stdout: &out}
+	c.printAssessmentHuman(app.PasswordAssessment{Strength: password.StrengthWeak, Length: 11, Alternative: "kW7!pQ3#zR8@"})
+	if !strings.Contains(out.String(), "Generierte Alternative (12 Zeichen): kW7!pQ3#zR8@\n") {
+		t.Fatalf("expected the alternative with its own length, got %q", out.String())
+	}
+}
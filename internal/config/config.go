@@ -7,6 +7,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/vectode/password-checker/internal/password"
 )
 
 const (
@@ -14,10 +16,20 @@ const (
 	envHIBPHTTPTimeout    = "HIBP_HTTP_TIMEOUT"
 	envHIBPUserAgent      = "HIBP_USER_AGENT"
 	envPasswordMinLength  = "PASSWORD_MIN_LENGTH"
+	envPasswordPolicy     = "PASSWORD_POLICY"
+	envKeyboardLayouts    = "PASSWORD_KEYBOARD_LAYOUTS"
+	envBannedLists        = "PASSWORD_BANNED_LISTS"
+	envBannedListCache    = "PASSWORD_BANNED_LIST_CACHE"
+	envOrganizationTerms  = "PASSWORD_ORGANIZATION_TERMS"
+	envDictionaries       = "PASSWORD_DICTIONARIES"
 	envGeneratorMinLength = "GENERATOR_MIN_LENGTH"
 	envGeneratorBits      = "GENERATOR_DEFAULT_BITS"
 	envCLImaxRetries      = "CLI_MAX_PROMPT_RETRIES"
 	envStoragePath        = "PASSWORD_STORE_PATH"
+	envSaveGateStrength   = "SAVE_GATE_MIN_STRENGTH"
+	envSaveGateBreached   = "SAVE_GATE_BLOCK_BREACHED"
+	envSaveGateVaults     = "SAVE_GATE_VAULTS"
+	envSiteProfilesPath   = "SITE_PROFILES_PATH"
 )
 
 // Config captures all runtime configuration used by the application.
@@ -27,11 +39,24 @@ type Config struct {
 	PwnedAPI  PwnedAPIConfig
 	CLI       CLIConfig
 	Storage   StorageConfig
+	SaveGate  SaveGateConfig
 }
 
 // PasswordConfig defines the runtime password policy.
 type PasswordConfig struct {
-	MinLength int
+	// Policy names a preset or the path of a policy file; MinLength only applies to the
+	// default preset.
+	Policy          string
+	MinLength       int
+	KeyboardLayouts []string
+	// BannedLists are plain-text or gzip files with one banned password per line.
+	BannedLists        []string
+	BannedListCacheDir string
+	// OrganizationTerms are company, product or location names no password may contain.
+	OrganizationTerms []string
+	// Dictionaries are frequency-ranked word list files or directories adding languages to
+	// the built-in German and English dictionaries.
+	Dictionaries []string
 }
 
 // GeneratorConfig controls secure password generation.
@@ -56,7 +81,14 @@ type CLIConfig struct {
 
 // StorageConfig defines persistence options for saved passwords.
 type StorageConfig struct {
-	Path string
+	Path             string
+	SiteProfilesPath string
+}
+
+// SaveGateConfig defines which passwords may be saved without --force.
+type SaveGateConfig struct {
+	MinStrength   string
+	BlockBreached bool
 }
 
 const (
@@ -69,13 +101,18 @@ const (
 	defaultBitsPerCharacter   = 5.95 // ~ log2(len(charset)) for defined charset
 	defaultCLIMaxRetries      = 3
 	defaultSpecialCharacters  = "!@#$%^&*()_+-=[]{}|;:,.<>?/"
+	defaultSaveGateStrength   = string(password.StrengthModerate)
+	defaultKeyboardLayouts    = "qwerty,qwertz,azerty,numpad"
+	defaultPasswordPolicy     = "default"
 )
 
 // Load reads configuration from environment variables and applies sensible defaults.
 func Load() (Config, error) {
 	cfg := Config{
 		Password: PasswordConfig{
-			MinLength: defaultPasswordMinLength,
+			Policy:          defaultPasswordPolicy,
+			MinLength:       defaultPasswordMinLength,
+			KeyboardLayouts: splitList(defaultKeyboardLayouts),
 		},
 		Generator: GeneratorConfig{
 			MinLength:           defaultGeneratorMinLength,
@@ -94,6 +131,10 @@ func Load() (Config, error) {
 		Storage: StorageConfig{
 			Path: defaultStoragePath(),
 		},
+		SaveGate: SaveGateConfig{
+			MinStrength:   defaultSaveGateStrength,
+			BlockBreached: true,
+		},
 	}
 
 	if baseURL := strings.TrimSpace(os.Getenv(envHIBPBaseURL)); baseURL != "" {
@@ -120,6 +161,32 @@ func Load() (Config, error) {
 		cfg.Password.MinLength = minLength
 	}
 
+	if policy := strings.TrimSpace(os.Getenv(envPasswordPolicy)); policy != "" {
+		cfg.Password.Policy = policy
+	}
+
+	if layoutsRaw := strings.TrimSpace(os.Getenv(envKeyboardLayouts)); layoutsRaw != "" {
+		layouts := splitList(layoutsRaw)
+		if len(layouts) == 0 {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envKeyboardLayouts, layoutsRaw)
+		}
+		cfg.Password.KeyboardLayouts = layouts
+	}
+
+	for _, path := range filepath.SplitList(os.Getenv(envBannedLists)) {
+		if path = strings.TrimSpace(path); path != "" {
+			cfg.Password.BannedLists = append(cfg.Password.BannedLists, path)
+		}
+	}
+
+	cfg.Password.OrganizationTerms = splitList(os.Getenv(envOrganizationTerms))
+
+	for _, path := range filepath.SplitList(os.Getenv(envDictionaries)) {
+		if path = strings.TrimSpace(path); path != "" {
+			cfg.Password.Dictionaries = append(cfg.Password.Dictionaries, path)
+		}
+	}
+
 	if generatorMinRaw := strings.TrimSpace(os.Getenv(envGeneratorMinLength)); generatorMinRaw != "" {
 		minLength, err := strconv.Atoi(generatorMinRaw)
 		if err != nil || minLength < 1 {
@@ -151,9 +218,93 @@ func Load() (Config, error) {
 		cfg.Storage.Path = storagePath
 	}
 
+	cfg.Storage.SiteProfilesPath = filepath.Join(filepath.Dir(cfg.Storage.Path), "site-profiles.json")
+	if profilesPath := strings.TrimSpace(os.Getenv(envSiteProfilesPath)); profilesPath != "" {
+		cfg.Storage.SiteProfilesPath = profilesPath
+	}
+
+	cfg.Password.BannedListCacheDir = filepath.Join(filepath.Dir(cfg.Storage.Path), "wordlist-cache")
+	if cacheDir := strings.TrimSpace(os.Getenv(envBannedListCache)); cacheDir != "" {
+		cfg.Password.BannedListCacheDir = cacheDir
+	}
+
+	if strengthRaw := strings.TrimSpace(os.Getenv(envSaveGateStrength)); strengthRaw != "" {
+		strength, err := password.ParseStrength(strengthRaw)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envSaveGateStrength, strengthRaw)
+		}
+		cfg.SaveGate.MinStrength = string(strength)
+	}
+
+	if breachedRaw := strings.TrimSpace(os.Getenv(envSaveGateBreached)); breachedRaw != "" {
+		block, err := strconv.ParseBool(breachedRaw)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %s", envSaveGateBreached, breachedRaw)
+		}
+		cfg.SaveGate.BlockBreached = block
+	}
+
+	if vaultsRaw := strings.TrimSpace(os.Getenv(envSaveGateVaults)); vaultsRaw != "" {
+		gate, err := vaultSaveGate(vaultsRaw, cfg.Storage.Path, cfg.SaveGate)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s value: %w", envSaveGateVaults, err)
+		}
+		cfg.SaveGate = gate
+	}
+
 	return cfg, nil
 }
 
+// vaultSaveGate applies the per-vault override matching storagePath. Overrides are
+// separated by semicolons and take the form "path=strength[:allow-breached]".
+func vaultSaveGate(raw, storagePath string, fallback SaveGateConfig) (SaveGateConfig, error) {
+	current := filepath.Clean(storagePath)
+	for _, item := range strings.Split(raw, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		path, settings, ok := strings.Cut(item, "=")
+		if !ok || strings.TrimSpace(path) == "" {
+			return SaveGateConfig{}, fmt.Errorf("expected path=strength, got %q", item)
+		}
+
+		gate := fallback
+		options := strings.Split(settings, ":")
+		strength, err := password.ParseStrength(options[0])
+		if err != nil {
+			return SaveGateConfig{}, fmt.Errorf("vault %s: %w", path, err)
+		}
+		gate.MinStrength = string(strength)
+		for _, option := range options[1:] {
+			switch strings.ToLower(strings.TrimSpace(option)) {
+			case "allow-breached":
+				gate.BlockBreached = false
+			case "block-breached":
+				gate.BlockBreached = true
+			default:
+				return SaveGateConfig{}, fmt.Errorf("vault %s: unknown option %q", path, option)
+			}
+		}
+
+		if filepath.Clean(strings.TrimSpace(path)) == current {
+			return gate, nil
+		}
+	}
+	return fallback, nil
+}
+
+// splitList splits a comma-separated value into lower-cased, non-empty items.
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func defaultStoragePath() string {
 	if home, err := os.UserHomeDir(); err == nil && strings.TrimSpace(home) != "" {
 		return filepath.Join(home, ".password-checker", "passwords.json")
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// configEnv lists every variable Load reads, so tests start from the defaults.
+var configEnv = []string{
+	envHIBPBaseURL, envHIBPHTTPTimeout, envHIBPUserAgent,
+	envPasswordMinLength, envPasswordPolicy, envKeyboardLayouts, envBannedLists, envBannedListCache,
+	envOrganizationTerms, envDictionaries, envGeneratorMinLength, envGeneratorBits, envCLImaxRetries,
+	envStoragePath, envSaveGateStrength, envSaveGateBreached, envSaveGateVaults, envSiteProfilesPath,
+}
+
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range configEnv {
+		t.Setenv(name, "")
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	clearEnv(t)
+	dir := t.TempDir()
+	t.Setenv(envStoragePath, filepath.Join(dir, "passwords.json"))
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Password.Policy != "default" || cfg.Password.MinLength != 12 {
+		t.Fatalf("unexpected password defaults: %+v", cfg.Password)
+	}
+	if strings.Join(cfg.Password.KeyboardLayouts, ",") != defaultKeyboardLayouts {
+		t.Fatalf("unexpected keyboard layouts: %v", cfg.Password.KeyboardLayouts)
+	}
+	if cfg.SaveGate != (SaveGateConfig{MinStrength: "moderate", BlockBreached: true}) {
+		t.Fatalf("unexpected save gate defaults: %+v", cfg.SaveGate)
+	}
+	if cfg.Storage.SiteProfilesPath != filepath.Join(dir, "site-profiles.json") || cfg.Password.BannedListCacheDir != filepath.Join(dir, "wordlist-cache") {
+		t.Fatalf("expected profiles and caches next to the vault, got %+v and %s", cfg.Storage, cfg.Password.BannedListCacheDir)
+	}
+}
+
+func TestLoadReadsPasswordAndSaveGateVariables(t *testing.T) {
+	clearEnv(t)
+	dir := t.TempDir()
+	vault := filepath.Join(dir, "vault", "passwords.json")
+	t.Setenv(envStoragePath, vault)
+	t.Setenv(envPasswordMinLength, "14")
+	t.Setenv(envPasswordPolicy, "bsi-orp4")
+	t.Setenv(envKeyboardLayouts, " QWERTZ , numpad ")
+	t.Setenv(envBannedLists, strings.Join([]string{"/lists/a.txt", " ", "/lists/b.txt.gz"}, string(filepath.ListSeparator)))
+	t.Setenv(envBannedListCache, "/cache")
+	t.Setenv(envOrganizationTerms, "Vectode, Berlin")
+	t.Setenv(envDictionaries, "/dictionaries")
+	t.Setenv(envSiteProfilesPath, "/profiles.json")
+	t.Setenv(envSaveGateStrength, " Strong ")
+	t.Setenv(envSaveGateBreached, "false")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Password.MinLength != 14 || cfg.Password.Policy != "bsi-orp4" {
+		t.Fatalf("unexpected policy settings: %+v", cfg.Password)
+	}
+	if strings.Join(cfg.Password.KeyboardLayouts, ",") != "qwertz,numpad" || strings.Join(cfg.Password.OrganizationTerms, ",") != "vectode,berlin" {
+		t.Fatalf("expected lower-cased lists, got %v and %v", cfg.Password.KeyboardLayouts, cfg.Password.OrganizationTerms)
+	}
+	if strings.Join(cfg.Password.BannedLists, ",") != "/lists/a.txt,/lists/b.txt.gz" || cfg.Password.BannedListCacheDir != "/cache" {
+		t.Fatalf("unexpected banned lists: %v in %s", cfg.Password.BannedLists, cfg.Password.BannedListCacheDir)
+	}
+	if len(cfg.Password.Dictionaries) != 1 || cfg.Storage.SiteProfilesPath != "/profiles.json" {
+		t.Fatalf("unexpected dictionaries or profiles: %v, %s", cfg.Password.Dictionaries, cfg.Storage.SiteProfilesPath)
+	}
+	if cfg.SaveGate != (SaveGateConfig{MinStrength: "strong", BlockBreached: false}) {
+		t.Fatalf("unexpected save gate: %+v", cfg.SaveGate)
+	}
+
+	t.Setenv(envSaveGateVaults, "/elsewhere/passwords.json=weak; "+vault+"=moderate:block-breached")
+	if cfg, err = Load(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.SaveGate != (SaveGateConfig{MinStrength: "moderate", BlockBreached: true}) {
+		t.Fatalf("expected the override of this vault, got %+v", cfg.SaveGate)
+	}
+}
+
+func TestLoadRejectsInvalidValues(t *testing.T) {
+	for name, value := range map[string]string{
+		envPasswordMinLength: "0",
+		envKeyboardLayouts:   ",",
+		envStoragePath:       "./",
+		envSaveGateStrength:  "unbreakable",
+		envSaveGateBreached:  "sometimes",
+		envSaveGateVaults:    "/vault/passwords.json=strong:maybe",
+	} {
+		t.Run(name, func(t *testing.T) {
+			clearEnv(t)
+			t.Setenv(envStoragePath, filepath.Join(t.TempDir(), "passwords.json"))
+			t.Setenv(name, value)
+			if _, err := Load(); err == nil || !strings.Contains(err.Error(), name) {
+				t.Fatalf("expected an error naming %s, got %v", name, err)
+			}
+		})
+	}
+}
@@ -1,32 +1,146 @@
 package password
 
-import "strings"
-
-var commonPasswords = map[string]struct{}{
-	"123456":    {},
-	"password":  {},
-	"123456789": {},
-	"12345678":  {},
-	"qwerty":    {},
-	"abc123":    {},
-	"password1": {},
-	"111111":    {},
-	"123123":    {},
-	"letmein":   {},
-	"welcome":   {},
-	"admin":     {},
-	"dragon":    {},
-	"football":  {},
-	"iloveyou":  {},
-	"monkey":    {},
-	"sunshine":  {},
-	"princess":  {},
-	"qwerty123": {},
-	"login":     {},
-}
-
-// IsCommonPassword returns true when the password is part of a curated list of common passwords.
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// commonPasswordList is ordered by prevalence; the position determines the dictionary rank.
+var commonPasswordList = []string{
+	"123456",
+	"password",
+	"123456789",
+	"12345678",
+	"qwerty",
+	"abc123",
+	"password1",
+	"111111",
+	"123123",
+	"letmein",
+	"welcome",
+	"admin",
+	"dragon",
+	"football",
+	"iloveyou",
+	"monkey",
+	"sunshine",
+	"princess",
+	"qwerty123",
+	"login",
+}
+
+var commonPasswords = func() map[string]struct{} {
+	set := make(map[string]struct{}, len(commonPasswordList))
+	for _, entry := range commonPasswordList {
+		set[entry] = struct{}{}
+	}
+	return set
+}()
+
+var commonPasswordDictionary = newRankedDictionary("passwords", commonPasswordList)
+
+// Transformations recognised when matching a password against the common-password list.
+const (
+	TransformationStripped   = "stripped"
+	TransformationL33t       = "l33t"
+	TransformationReversed   = "reversed"
+	TransformationConfusable = "confusable"
+)
+
+// CommonPasswordMatch explains how a password was derived from a common password.
+// Transformations is empty for an exact (case-insensitive) match.
+type CommonPasswordMatch struct {
+	BaseWord        string
+	Transformations []string
+}
+
+// IsCommonPassword returns true when the password is part of a curated list of common passwords,
+// possibly disguised by l33t substitutions, surrounding digits and symbols, or reversal.
 func IsCommonPassword(password string) bool {
-	_, ok := commonPasswords[strings.ToLower(password)]
+	_, ok := MatchCommonPassword(password)
 	return ok
 }
+
+// MatchCommonPassword looks the password up in the common-password list. Before the lookup it
+// tries, from the least to the most transformed form: replacing lookalike characters by their
+// UTS #39 prototypes, stripping leading and trailing digits and symbols, undoing l33t
+// substitutions and reversing the string.
+func MatchCommonPassword(password string) (CommonPasswordMatch, bool) {
+	type candidate struct {
+		value           string
+		transformations []string
+	}
+
+	lowered := strings.ToLower(password)
+	forms := []candidate{{value: lowered}}
+	if skeleton := strings.ToLower(Skeleton(password)); skeleton != norm.NFD.String(lowered) {
+		forms = append(forms, candidate{skeleton, []string{TransformationConfusable}})
+	}
+	for _, form := range forms {
+		if stripped := stripAffixes(form.value); stripped != "" && stripped != form.value {
+			forms = append(forms, candidate{stripped, withTransformation(form.transformations, TransformationStripped)})
+		}
+	}
+	// Ranging over forms visits only the untranslated forms; the readings are appended behind them.
+	for _, form := range forms {
+		for _, reading := range unl33t(form.value) {
+			forms = append(forms, candidate{reading, withTransformation(form.transformations, TransformationL33t)})
+		}
+	}
+
+	for _, reversed := range []bool{false, true} {
+		for _, form := range forms {
+			value, transformations := form.value, form.transformations
+			if reversed {
+				value = reverseString(value)
+				transformations = withTransformation(transformations, TransformationReversed)
+			}
+			if _, ok := commonPasswords[value]; ok {
+				return CommonPasswordMatch{BaseWord: value, Transformations: transformations}, true
+			}
+		}
+	}
+	return CommonPasswordMatch{}, false
+}
+
+func withTransformation(transformations []string, transformation string) []string {
+	extended := make([]string, 0, len(transformations)+1)
+	extended = append(extended, transformations...)
+	return append(extended, transformation)
+}
+
+// stripAffixes removes leading and trailing digits and symbols.
+func stripAffixes(value string) string {
+	return strings.TrimFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
+
+// unl33t returns the readings of value with its l33t characters replaced by letters.
+func unl33t(value string) []string {
+	runes := []rune(value)
+	subs := l33tSubstitutions(runes)
+	readings := make([]string, 0, len(subs))
+	for _, sub := range subs {
+		translated := make([]rune, len(runes))
+		for idx, r := range runes {
+			if letter, ok := sub[r]; ok {
+				translated[idx] = letter
+				continue
+			}
+			translated[idx] = r
+		}
+		readings = append(readings, string(translated))
+	}
+	return readings
+}
+
+func reverseString(value string) string {
+	runes := []rune(value)
+	for left, right := 0, len(runes)-1; left < right; left, right = left+1, right-1 {
+		runes[left], runes[right] = runes[right], runes[left]
+	}
+	return string(runes)
+}
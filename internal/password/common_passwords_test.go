@@ -0,0 +1,38 @@
+package password
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchCommonPasswordTransformations(t *testing.T) {
+	cases := []struct {
+		password        string
+		base            string
+		transformations []string
+	}{
+		{"Password", "password", nil},
+		{"P@ssw0rd!", "password", []string{TransformationStripped, TransformationL33t}},
+		{"Dr4g0n2024", "dragon", []string{TransformationStripped, TransformationL33t}},
+		{"2024Welcome!!", "welcome", []string{TransformationStripped}},
+		{"nimda", "admin", []string{TransformationReversed}},
+		{"y3kn0m#1", "monkey", []string{TransformationStripped, TransformationL33t, TransformationReversed}},
+	}
+	for _, tc := range cases {
+		match, ok := MatchCommonPassword(tc.password)
+		if !ok {
+			t.Fatalf("%s: expected a common password match", tc.password)
+		}
+		if match.BaseWord != tc.base || !reflect.DeepEqual(match.Transformations, tc.transformations) {
+			t.Fatalf("%s: unexpected match %+v", tc.password, match)
+		}
+	}
+}
+
+func TestMatchCommonPasswordRejectsUnrelated(t *testing.T) {
+	for _, password := range []string{"Tr0ub4dor&3", "correct-horse-battery", "7$kQ!x"} {
+		if match, ok := MatchCommonPassword(password); ok {
+			t.Fatalf("%s: unexpected match %+v", password, match)
+		}
+	}
+}
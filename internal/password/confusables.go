@@ -0,0 +1,194 @@
+package password
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// confusables maps characters to the ASCII prototype they are visually confusable with. It is
+// the subset of the Unicode confusables data (UTS #39) covering lookalikes of ASCII letters and
+// digits from the Cyrillic, Greek, Armenian and extended Latin ranges; full-width and
+// mathematical forms are already folded by NFKC.
+var confusables = map[rune]string{
+	// Cyrillic
+	'а': "a", 'в': "B", 'г': "r", 'е': "e", 'к': "K", 'м': "M", 'н': "H", 'о': "o", 'п': "n",
+	'р': "p", 'с': "c", 'т': "T", 'у': "y", 'х': "x", 'ь': "b", 'ѕ': "s", 'і': "i", 'ј': "j",
+	'ԁ': "d", 'ԛ': "q", 'ԝ': "w", 'һ': "h", 'ӏ': "l", 'ѵ': "v", 'ү': "y", 'б': "6", 'з': "3",
+	'А': "A", 'В': "B", 'Е': "E", 'З': "3", 'К': "K", 'М': "M", 'Н': "H", 'О': "O", 'Р': "P",
+	'С': "C", 'Т': "T", 'У': "Y", 'Х': "X", 'Ѕ': "S", 'І': "l", 'Ј': "J", 'Ԛ': "Q", 'Ԝ': "W",
+	'Ү': "Y", 'Ӏ': "l", 'Ь': "b",
+	// Greek
+	'α': "a", 'γ': "y", 'ι': "i", 'ν': "v", 'ο': "o", 'ρ': "p", 'υ': "u", 'ϲ': "c", 'ϳ': "j",
+	'Α': "A", 'Β': "B", 'Ε': "E", 'Ζ': "Z", 'Η': "H", 'Ι': "l", 'Κ': "K", 'Μ': "M", 'Ν': "N",
+	'Ο': "O", 'Ρ': "P", 'Τ': "T", 'Υ': "Y", 'Χ': "X", 'Ϲ': "C",
+	// Armenian
+	'օ': "o", 'ս': "u", 'հ': "h", 'ո': "n", 'ց': "g", 'զ': "q", 'Օ': "O", 'Ս': "U", 'Տ': "S",
+	// Latin
+	'ı': "i", 'ȷ': "j", 'ɑ': "a", 'ɡ': "g", 'ɩ': "i", 'ʏ': "y", 'ᴠ': "v", 'ᴡ': "w", 'ᴢ': "z",
+}
+
+// Skeleton computes the UTS #39 skeleton of the string: the decomposed string with every
+// confusable character replaced by its prototype. Two strings that look alike share a skeleton.
+func Skeleton(value string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(value) {
+		if prototype, ok := confusables[r]; ok {
+			b.WriteString(prototype)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return norm.NFD.String(b.String())
+}
+
+// invisible reports format characters such as zero-width joiners and bidirectional controls,
+// variation selectors, the combining grapheme joiner and Hangul fillers, which render as nothing.
+func invisible(r rune) bool {
+	switch {
+	case unicode.Is(unicode.Cf, r), unicode.Is(unicode.Variation_Selector, r):
+		return true
+	}
+	switch r {
+	case '\u034f', '\u115f', '\u1160', '\u3164', '\uffa0':
+		return true
+	}
+	return false
+}
+
+// confusableFindings warns about characters that cause login failures across devices:
+// invisible characters, letters mixing scripts that should not be mixed and non-Latin
+// letters that look like the Latin letters around them.
+func confusableFindings(password string) []Finding {
+	var findings []Finding
+	runes := []rune(password)
+
+	var hidden []string
+	for i, r := range runes {
+		if invisible(r) {
+			hidden = append(hidden, fmt.Sprintf("U+%04X at position %d", r, i+1))
+		}
+	}
+	if len(hidden) > 0 {
+		findings = append(findings, Finding{
+			Code:        "charset.invisible",
+			Message:     "password contains invisible characters: " + strings.Join(hidden, ", "),
+			Severity:    SeverityWarn,
+			Requirement: "confusable_characters",
+		})
+	}
+
+	scripts, positions := letterScripts(runes)
+	if mixedScripts(scripts) {
+		findings = append(findings, mixedScriptFinding(scripts, positions))
+	}
+
+	// Lookalikes only matter next to the Latin letters they imitate.
+	if len(positions["Latin"]) == 0 {
+		return findings
+	}
+	var lookalikes []string
+	for i, r := range runes {
+		if prototype, ok := confusables[r]; ok && letterScript(r) != "Latin" {
+			lookalikes = append(lookalikes, fmt.Sprintf("%q (U+%04X) at position %d looks like %q", r, r, i+1, prototype))
+		}
+	}
+	if len(lookalikes) > 0 {
+		findings = append(findings, Finding{
+			Code:        "charset.confusable",
+			Message:     "password contains lookalike characters: " + strings.Join(lookalikes, ", "),
+			Severity:    SeverityWarn,
+			Requirement: "confusable_characters",
+		})
+	}
+	return findings
+}
+
+func mixedScriptFinding(scripts []string, positions map[string][]int) Finding {
+	main := scripts[0]
+	for _, script := range scripts[1:] {
+		if len(positions[script]) > len(positions[main]) {
+			main = script
+		}
+	}
+	var minority []string
+	for _, script := range scripts {
+		if script != main {
+			minority = append(minority, fmt.Sprintf("%s at %s", script, joinPositions(positions[script])))
+		}
+	}
+	return Finding{
+		Code:        "charset.mixed_script",
+		Message:     fmt.Sprintf("password mixes the %s scripts (%s)", strings.Join(scripts, ", "), strings.Join(minority, "; ")),
+		Severity:    SeverityWarn,
+		Requirement: "confusable_characters",
+	}
+}
+
+// letterScripts returns the scripts of the letters in order of first appearance together
+// with the 1-based positions of each script's letters. Shared characters are ignored.
+func letterScripts(runes []rune) ([]string, map[string][]int) {
+	var scripts []string
+	positions := make(map[string][]int)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		script := letterScript(r)
+		if script == "" || script == "Common" || script == "Inherited" {
+			continue
+		}
+		if _, ok := positions[script]; !ok {
+			scripts = append(scripts, script)
+		}
+		positions[script] = append(positions[script], i+1)
+	}
+	return scripts, positions
+}
+
+// cjkScriptSets are the script combinations written together in Japanese, Korean and Chinese.
+var cjkScriptSets = []map[string]bool{
+	{"Han": true, "Hiragana": true, "Katakana": true},
+	{"Han": true, "Hangul": true},
+	{"Han": true, "Bopomofo": true},
+}
+
+// mixedScripts applies the UTS #39 moderately restrictive profile: Latin may be combined with
+// a CJK script set or with one other script except Cyrillic and Greek, whose letters are the
+// most common lookalikes of Latin ones.
+func mixedScripts(scripts []string) bool {
+	others := make([]string, 0, len(scripts))
+	for _, script := range scripts {
+		if script != "Latin" {
+			others = append(others, script)
+		}
+	}
+	if len(others) <= 1 {
+		latin := len(others) < len(scripts)
+		return latin && len(others) == 1 && (others[0] == "Cyrillic" || others[0] == "Greek")
+	}
+	for _, set := range cjkScriptSets {
+		covered := true
+		for _, script := range others {
+			covered = covered && set[script]
+		}
+		if covered {
+			return false
+		}
+	}
+	return true
+}
+
+func joinPositions(positions []int) string {
+	parts := make([]string, len(positions))
+	for i, position := range positions {
+		parts[i] = fmt.Sprint(position)
+	}
+	label := "position "
+	if len(positions) > 1 {
+		label = "positions "
+	}
+	return label + strings.Join(parts, ", ")
+}
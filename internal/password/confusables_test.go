@@ -0,0 +1,66 @@
+package password
+
+import (
+	"strings"
+	"testing"
+)
+
+func findingMessage(findings []Finding, code string) string {
+	for _, finding := range findings {
+		if finding.Code == code {
+			return finding.Message
+		}
+	}
+	return ""
+}
+
+func TestSkeletonFoldsLookalikes(t *testing.T) {
+	if got := Skeleton("P\u0430yp\u0430l"); got != "Paypal" {
+		t.Fatalf("expected Cyrillic a to fold to Latin a, got %q", got)
+	}
+	if Skeleton("\u0421\u0410\u0422") != Skeleton("CAT") {
+		t.Fatal("expected Cyrillic CAT and Latin CAT to share a skeleton")
+	}
+}
+
+func TestConfusableFindingsReportPositions(t *testing.T) {
+	findings := confusableFindings("Harb\u043er-Lantern-42")
+	if !strings.Contains(findingMessage(findings, "charset.mixed_script"), "Cyrillic at position 5") {
+		t.Fatalf("expected mixed-script finding with position, got %+v", findings)
+	}
+	if !strings.Contains(findingMessage(findings, "charset.confusable"), "U+043E) at position 5 looks like \"o\"") {
+		t.Fatalf("expected confusable finding with position, got %+v", findings)
+	}
+}
+
+func TestConfusableFindingsReportInvisibleCharacters(t *testing.T) {
+	findings := confusableFindings("Harbor\u200dLantern\u00ad42")
+	message := findingMessage(findings, "charset.invisible")
+	if !strings.Contains(message, "U+200D at position 7") || !strings.Contains(message, "U+00AD at position 15") {
+		t.Fatalf("expected invisible characters with positions, got %+v", findings)
+	}
+}
+
+func TestConfusableFindingsAllowRegularScriptMixes(t *testing.T) {
+	for _, password := range []string{
+		"Harbor Lantern 42",
+		"Москва-зимой-2024",
+		"Tokyo東京タワーの夜",
+		"Seoul서울市",
+		"Shalomשלום",
+	} {
+		if findings := confusableFindings(password); len(findings) != 0 {
+			t.Fatalf("%s: unexpected findings %+v", password, findings)
+		}
+	}
+	if !hasFinding(confusableFindings("αβγабв"), "charset.mixed_script") {
+		t.Fatal("expected Greek and Cyrillic to be reported as a mix")
+	}
+}
+
+func TestCommonPasswordWithLookalikes(t *testing.T) {
+	match, ok := MatchCommonPassword("p\u0430ssw\u043erd")
+	if !ok || match.BaseWord != "password" || match.Transformations[0] != TransformationConfusable {
+		t.Fatalf("expected confusable match of password, got %+v, %v", match, ok)
+	}
+}
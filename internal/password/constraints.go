@@ -0,0 +1,238 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// maxConstrainedAttempts bounds the retries spent avoiding forbidden sequences.
+const maxConstrainedAttempts = 200
+
+// CharacterClass identifies a class of characters a password may be required to contain.
+type CharacterClass string
+
+const (
+	ClassLower   CharacterClass = "lower"
+	ClassUpper   CharacterClass = "upper"
+	ClassDigit   CharacterClass = "digit"
+	ClassSpecial CharacterClass = "special"
+	// ClassLetter matches any letter regardless of case.
+	ClassLetter CharacterClass = "letter"
+	// ClassCaseless matches letters of scripts without case, such as Han, Kana, Arabic or Hebrew.
+	ClassCaseless CharacterClass = "caseless"
+)
+
+// ParseCharacterClass converts user input into a CharacterClass.
+func ParseCharacterClass(value string) (CharacterClass, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "lower", "lowercase":
+		return ClassLower, nil
+	case "upper", "uppercase":
+		return ClassUpper, nil
+	case "digit", "digits", "numeric":
+		return ClassDigit, nil
+	case "special", "symbol", "symbols":
+		return ClassSpecial, nil
+	case "letter", "letters", "alpha", "alphabetic":
+		return ClassLetter, nil
+	case "caseless", "caseless-letter", "caseless-letters":
+		return ClassCaseless, nil
+	default:
+		return "", fmt.Errorf("unknown character class: %s", value)
+	}
+}
+
+// Constraints narrows password generation to what a particular site accepts.
+// Zero values fall back to the generator policy.
+type Constraints struct {
+	MaxLength           int
+	AllowedCharset      string
+	ForbiddenCharacters string
+	RequiredClasses     []CharacterClass
+	ForbiddenSequences  []string
+}
+
+// Generated is a generated password together with the entropy it actually carries.
+type Generated struct {
+	Password    string
+	EntropyBits float64
+}
+
+// GenerateConstrained returns a password targeting the requested entropy within the constraints.
+// When MaxLength prevents reaching the target, the longest permitted password is produced and
+// EntropyBits reports the lower entropy actually achieved. EntropyBits adds up log2 of the
+// alphabet size of every position: one character from each required class and the rest
+// from the allowed charset without the forbidden characters.
+func (g *Generator) GenerateConstrained(bits int, constraints Constraints) (Generated, error) {
+	if bits <= 0 {
+		return Generated{}, errors.New("bits must be greater than zero")
+	}
+
+	charset := g.charset
+	if constraints.AllowedCharset != "" {
+		charset = uniqueRunes(constraints.AllowedCharset)
+	}
+	if constraints.ForbiddenCharacters != "" {
+		charset = withoutRunes(charset, constraints.ForbiddenCharacters)
+	}
+	if len(charset) < 2 {
+		return Generated{}, errors.New("allowed charset must contain at least two distinct characters")
+	}
+
+	required := constraints.RequiredClasses
+	if len(required) == 0 && constraints.AllowedCharset == "" {
+		// Classes removed entirely by ForbiddenCharacters are no longer required.
+		for _, class := range []CharacterClass{ClassLower, ClassUpper, ClassDigit, ClassSpecial} {
+			if len(classRunes(charset, class)) > 0 {
+				required = append(required, class)
+			}
+		}
+	}
+	requiredSets := make([][]rune, 0, len(required))
+	for _, class := range required {
+		set := classRunes(charset, class)
+		if len(set) == 0 {
+			return Generated{}, fmt.Errorf("allowed charset contains no %s characters", class)
+		}
+		requiredSets = append(requiredSets, set)
+	}
+
+	// A position forced into a required class only carries the entropy of that class.
+	bitsPerCharacter := math.Log2(float64(len(charset)))
+	requiredBits := 0.0
+	for _, set := range requiredSets {
+		requiredBits += math.Log2(float64(len(set)))
+	}
+	length := len(requiredSets) + int(math.Ceil(math.Max(0, float64(bits)-requiredBits)/bitsPerCharacter))
+	if length < g.policy.MinLength {
+		length = g.policy.MinLength
+	}
+	if constraints.MaxLength > 0 && length > constraints.MaxLength {
+		length = constraints.MaxLength
+	}
+	if length < len(requiredSets) {
+		return Generated{}, fmt.Errorf("password length %d insufficient to satisfy required character sets", length)
+	}
+
+	for attempt := 0; attempt < maxConstrainedAttempts; attempt++ {
+		candidate, err := generateFromSets(length, charset, requiredSets)
+		if err != nil {
+			return Generated{}, err
+		}
+		if containsForbiddenSequence(candidate, constraints.ForbiddenSequences) {
+			continue
+		}
+		entropy := requiredBits + float64(length-len(requiredSets))*bitsPerCharacter
+		return Generated{Password: candidate, EntropyBits: entropy}, nil
+	}
+	return Generated{}, errors.New("could not generate a password without forbidden sequences")
+}
+
+func generateFromSets(length int, charset []rune, requiredSets [][]rune) (string, error) {
+	password := make([]rune, length)
+	idx := 0
+	for _, set := range requiredSets {
+		r, err := randomRuneFromRunes(set)
+		if err != nil {
+			return "", err
+		}
+		password[idx] = r
+		idx++
+	}
+	for idx < length {
+		r, err := randomRuneFromRunes(charset)
+		if err != nil {
+			return "", err
+		}
+		password[idx] = r
+		idx++
+	}
+	if err := shuffleRunes(password); err != nil {
+		return "", err
+	}
+	return string(password), nil
+}
+
+func containsForbiddenSequence(password string, sequences []string) bool {
+	lowered := strings.ToLower(password)
+	for _, sequence := range sequences {
+		if sequence != "" && strings.Contains(lowered, strings.ToLower(sequence)) {
+			return true
+		}
+	}
+	return false
+}
+
+func classRunes(charset []rune, class CharacterClass) []rune {
+	var set []rune
+	for _, r := range charset {
+		if class.contains(r) {
+			set = append(set, r)
+		}
+	}
+	return set
+}
+
+func (c CharacterClass) contains(r rune) bool {
+	if c == ClassLetter {
+		return unicode.IsLetter(r)
+	}
+	return runeClass(r) == c
+}
+
+// findingName is the word used for the class in finding codes and messages.
+func (c CharacterClass) findingName() string {
+	switch c {
+	case ClassLower:
+		return "lowercase"
+	case ClassUpper:
+		return "uppercase"
+	case ClassDigit:
+		return "numeric"
+	case ClassLetter:
+		return "alphabetic"
+	default:
+		return string(c)
+	}
+}
+
+func runeClass(r rune) CharacterClass {
+	switch {
+	case unicode.IsUpper(r), unicode.IsTitle(r):
+		return ClassUpper
+	case unicode.IsLower(r):
+		return ClassLower
+	case unicode.IsLetter(r):
+		return ClassCaseless
+	case unicode.IsDigit(r):
+		return ClassDigit
+	default:
+		return ClassSpecial
+	}
+}
+
+func withoutRunes(charset []rune, forbidden string) []rune {
+	kept := make([]rune, 0, len(charset))
+	for _, r := range charset {
+		if !strings.ContainsRune(forbidden, r) {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+func uniqueRunes(value string) []rune {
+	seen := make(map[rune]struct{}, len(value))
+	runes := make([]rune, 0, len(value))
+	for _, r := range value {
+		if _, ok := seen[r]; ok {
+			continue
+		}
+		seen[r] = struct{}{}
+		runes = append(runes, r)
+	}
+	return runes
+}
@@ -0,0 +1,67 @@
+package password
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestGenerateConstrainedRespectsProfile(t *testing.T) {
+	generator, err := NewGenerator(GeneratorPolicy{MinLength: 16, BitsPerCharacter: 5.95, SpecialCharset: "!@#"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	constraints := Constraints{
+		MaxLength:          10,
+		AllowedCharset:     lowerCharset + digitCharset + "-",
+		RequiredClasses:    []CharacterClass{ClassLower, ClassDigit},
+		ForbiddenSequences: []string{"a"},
+	}
+	for i := 0; i < 20; i++ {
+		generated, err := generator.GenerateConstrained(128, constraints)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(generated.Password) != 10 {
+			t.Fatalf("expected length 10, got %d", len(generated.Password))
+		}
+		if strings.ContainsAny(generated.Password, upperCharset+"!@#") || strings.Contains(generated.Password, "a") {
+			t.Fatalf("password %q violates profile", generated.Password)
+		}
+		if !containsAny(generated.Password, digitCharset) || !containsAny(generated.Password, lowerCharset) {
+			t.Fatalf("password %q misses a required class", generated.Password)
+		}
+		// One lowercase letter, one digit and eight characters from the 37 allowed.
+		if want := math.Log2(26) + math.Log2(10) + 8*math.Log2(37); math.Abs(generated.EntropyBits-want) > 1e-9 {
+			t.Fatalf("expected %.1f bits for the capped length, got %.1f", want, generated.EntropyBits)
+		}
+	}
+}
+
+func TestGenerateConstrainedRejectsImpossibleClasses(t *testing.T) {
+	generator, err := NewGenerator(GeneratorPolicy{MinLength: 16, BitsPerCharacter: 5.95, SpecialCharset: "!@#"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = generator.GenerateConstrained(64, Constraints{AllowedCharset: digitCharset, RequiredClasses: []CharacterClass{ClassUpper}})
+	if err == nil {
+		t.Fatalf("expected error when required class is not in the allowed charset")
+	}
+}
+
+func TestGenerateConstrainedDropsForbiddenCharacters(t *testing.T) {
+	generator, err := NewGenerator(GeneratorPolicy{MinLength: 16, BitsPerCharacter: 5.95, SpecialCharset: "!@#"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < 20; i++ {
+		generated, err := generator.GenerateConstrained(96, Constraints{MaxLength: 16, ForbiddenCharacters: "!@#"})
+		if err != nil {
+			t.Fatalf("expected the special class to stop being required, got %v", err)
+		}
+		if strings.ContainsAny(generated.Password, "!@#") {
+			t.Fatalf("expected no forbidden characters, got %q", generated.Password)
+		}
+	}
+}
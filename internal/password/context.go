@@ -0,0 +1,197 @@
+package password
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// minContextTermLength is the shortest context term checked; shorter fragments such as
+// initials would match too many unrelated passwords.
+const minContextTermLength = 3
+
+// EvaluationContext describes who uses a password and where, so passwords derived from
+// that context can be rejected as NIST SP 800-63B requires.
+type EvaluationContext struct {
+	Username string
+	Email    string
+	Label    string
+	// URL is the address or host name of the service the password is used for.
+	URL               string
+	OrganizationTerms []string
+
+	// matches are the estimate's matches, set by the evaluator for its built-in rules.
+	matches []Match
+}
+
+// contextTerm is a single word taken from the evaluation context, tagged with its source.
+type contextTerm struct {
+	source string
+	term   string
+}
+
+// Context term sources, used as the suffix of the context.* finding codes.
+const (
+	contextSourceUsername     = "username"
+	contextSourceEmail        = "email"
+	contextSourceLabel        = "label"
+	contextSourceService      = "service"
+	contextSourceOrganization = "organization"
+)
+
+// freemailDomains are e-mail providers whose name says nothing about the user.
+var freemailDomains = map[string]struct{}{
+	"gmail": {}, "googlemail": {}, "outlook": {}, "hotmail": {}, "live": {}, "yahoo": {},
+	"icloud": {}, "gmx": {}, "web": {}, "t-online": {}, "posteo": {}, "proton": {}, "protonmail": {},
+}
+
+// terms extracts the distinct words of the context, including the organisation terms of the policy.
+func (c EvaluationContext) terms(organizationTerms []string) []contextTerm {
+	var terms []contextTerm
+	seen := map[string]struct{}{}
+	add := func(source string, values ...string) {
+		for _, value := range values {
+			value = strings.ToLower(strings.TrimSpace(Normalise(value)))
+			if len([]rune(value)) < minContextTermLength {
+				continue
+			}
+			if _, ok := seen[value]; ok {
+				continue
+			}
+			seen[value] = struct{}{}
+			terms = append(terms, contextTerm{source: source, term: value})
+		}
+	}
+
+	add(contextSourceUsername, c.Username)
+	add(contextSourceUsername, splitWords(c.Username)...)
+
+	if local, domain, ok := strings.Cut(c.Email, "@"); ok {
+		add(contextSourceEmail, local)
+		add(contextSourceEmail, splitWords(local)...)
+		if name := siteName(domain); name != "" {
+			if _, free := freemailDomains[name]; !free {
+				add(contextSourceEmail, name)
+			}
+		}
+	} else {
+		add(contextSourceEmail, c.Email)
+	}
+
+	add(contextSourceLabel, c.Label)
+	add(contextSourceLabel, splitWords(c.Label)...)
+	add(contextSourceService, siteName(c.URL))
+
+	for _, term := range append(append([]string(nil), organizationTerms...), c.OrganizationTerms...) {
+		add(contextSourceOrganization, term)
+	}
+	return terms
+}
+
+// splitWords breaks a value such as "jane.doe-42" into its letter and digit runs.
+func splitWords(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// siteName reduces a URL or host name to the label users associate with the service,
+// e.g. "https://www.login.acme.de/path" becomes "acme".
+func siteName(raw string) string {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if raw == "" {
+		return ""
+	}
+	host := raw
+	if !strings.Contains(raw, "://") {
+		raw = "//" + raw
+	}
+	if parsed, err := url.Parse(raw); err == nil && parsed.Hostname() != "" {
+		host = parsed.Hostname()
+	}
+
+	labels := strings.Split(strings.Trim(host, "."), ".")
+	if len(labels) == 1 {
+		return labels[0]
+	}
+	return labels[len(labels)-2]
+}
+
+// contextFindings reports every context term the password contains, even when the term
+// is disguised by l33t substitutions or written backwards.
+func contextFindings(password string, terms []contextTerm) []Finding {
+	if len(terms) == 0 {
+		return nil
+	}
+
+	lowered := strings.ToLower(password)
+	type form struct {
+		value           string
+		transformations []string
+	}
+	forms := []form{{value: lowered}}
+	for _, reading := range unl33t(lowered) {
+		forms = append(forms, form{reading, []string{TransformationL33t}})
+	}
+	for _, f := range forms[:len(forms):len(forms)] {
+		forms = append(forms, form{reverseString(f.value), withTransformation(f.transformations, TransformationReversed)})
+	}
+
+	// Longer terms are reported first so "jane.doe" is not also reported as "jane" and "doe".
+	ordered := append([]contextTerm(nil), terms...)
+	sort.SliceStable(ordered, func(a, b int) bool { return len(ordered[a].term) > len(ordered[b].term) })
+
+	var (
+		findings []Finding
+		reported []string
+	)
+	for _, term := range ordered {
+		if withinReported(reported, term.term) {
+			continue
+		}
+		for _, f := range forms {
+			if !strings.Contains(f.value, term.term) {
+				continue
+			}
+			message := fmt.Sprintf("password contains the %s %q", contextSourceNoun(term.source), term.term)
+			if len(f.transformations) > 0 {
+				message += fmt.Sprintf(" (%s)", strings.Join(f.transformations, ", "))
+			}
+			findings = append(findings, Finding{
+				Code:        "context." + term.source,
+				Message:     message,
+				Severity:    SeverityError,
+				Requirement: "context_terms",
+			})
+			reported = append(reported, term.term)
+			break
+		}
+	}
+	return findings
+}
+
+func withinReported(values []string, term string) bool {
+	for _, value := range values {
+		if strings.Contains(value, term) {
+			return true
+		}
+	}
+	return false
+}
+
+func contextSourceNoun(source string) string {
+	switch source {
+	case contextSourceEmail:
+		return "e-mail address term"
+	case contextSourceLabel:
+		return "entry label term"
+	case contextSourceService:
+		return "service name"
+	case contextSourceOrganization:
+		return "organization term"
+	default:
+		return source
+	}
+}
@@ -0,0 +1,65 @@
+package password
+
+import "testing"
+
+func TestAssessWithContextReportsDerivedPasswords(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 8, OrganizationTerms: []string{"Vectode"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	evaluation := EvaluationContext{
+		Username: "jane.doe",
+		Email:    "jane.doe@acme-corp.de",
+		Label:    "GitLab Work",
+		URL:      "https://login.github.com/session",
+	}
+
+	cases := []struct {
+		password string
+		code     string
+		message  string
+	}{
+		{"Jane.Doe!2024#x", "context.username", `password contains the username "jane.doe"`},
+		{"xQ!8acme-corp7Z", "context.email", `password contains the e-mail address term "acme-corp"`},
+		{"G1tL4b#Secure99", "context.label", `password contains the entry label term "gitlab" (l33t)`},
+		{"9#buhtig!QxZ", "context.service", `password contains the service name "github" (reversed)`},
+		{"V3ct0de-Sommer!", "context.organization", `password contains the organization term "vectode" (l33t)`},
+	}
+	for _, tc := range cases {
+		assessment := evaluator.AssessWithContext(tc.password, evaluation)
+		found := false
+		for _, finding := range assessment.Findings {
+			if finding.Code == tc.code {
+				found = true
+				if finding.Message != tc.message {
+					t.Fatalf("%s: unexpected message %q", tc.password, finding.Message)
+				}
+			}
+		}
+		if !found {
+			t.Fatalf("%s: expected %s finding, got %+v", tc.password, tc.code, assessment.Findings)
+		}
+		if assessment.Strength != StrengthWeak {
+			t.Fatalf("%s: expected weak strength, got %s", tc.password, assessment.Strength)
+		}
+	}
+}
+
+func TestContextTermsLowerGuessEstimate(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	without := evaluator.Assess("kowalczyk")
+	with := evaluator.AssessWithContext("kowalczyk", EvaluationContext{Username: "kowalczyk"})
+	if with.GuessesLog10 >= without.GuessesLog10 {
+		t.Fatalf("expected context to lower the estimate: %.1f >= %.1f", with.GuessesLog10, without.GuessesLog10)
+	}
+}
+
+func TestContextTermsSkipFreemailAndShortTerms(t *testing.T) {
+	terms := EvaluationContext{Username: "jd", Email: "jd@gmail.com"}.terms(nil)
+	if len(terms) != 0 {
+		t.Fatalf("expected no terms, got %+v", terms)
+	}
+}
@@ -0,0 +1,97 @@
+package password
+
+import (
+	"fmt"
+	"math"
+)
+
+// AttackScenario names an attacker model used for crack-time estimates.
+type AttackScenario string
+
+const (
+	// ScenarioOnlineThrottled is an online attack against a service that rate-limits logins.
+	ScenarioOnlineThrottled AttackScenario = "online_throttled"
+	// ScenarioOnlineUnthrottled is an online attack against a service without rate limiting.
+	ScenarioOnlineUnthrottled AttackScenario = "online_unthrottled"
+	// ScenarioOfflineSlowHash is an offline attack on a leaked bcrypt, scrypt or Argon2 hash.
+	ScenarioOfflineSlowHash AttackScenario = "offline_slow_hash"
+	// ScenarioOfflineFastHash is an offline attack on a leaked SHA-1, MD5 or NTLM hash using GPUs.
+	ScenarioOfflineFastHash AttackScenario = "offline_fast_hash"
+)
+
+// attackRates lists the assumed guesses per second for each scenario, ordered from slowest to fastest.
+var attackRates = []struct {
+	scenario AttackScenario
+	rate     float64
+}{
+	{ScenarioOnlineThrottled, 100.0 / 3600},
+	{ScenarioOnlineUnthrottled, 10},
+	{ScenarioOfflineSlowHash, 1e4},
+	{ScenarioOfflineFastHash, 1e10},
+}
+
+// CrackTime estimates how long an attacker needs to guess a password in one scenario.
+type CrackTime struct {
+	Scenario         AttackScenario `json:"scenario"`
+	GuessesPerSecond float64        `json:"guesses_per_second"`
+	Seconds          float64        `json:"seconds"`
+	Display          string         `json:"display"`
+}
+
+// EstimateCrackTimes converts a guess count into crack times for every attack scenario.
+// Durations too long for a float64 are capped at math.MaxFloat64 so they stay encodable.
+func EstimateCrackTimes(guesses float64) []CrackTime {
+	times := make([]CrackTime, 0, len(attackRates))
+	for _, attack := range attackRates {
+		seconds := math.Min(guesses/attack.rate, math.MaxFloat64)
+		times = append(times, CrackTime{
+			Scenario:         attack.scenario,
+			GuessesPerSecond: attack.rate,
+			Seconds:          seconds,
+			Display:          DisplayDuration(seconds),
+		})
+	}
+	return times
+}
+
+// DisplayDuration renders a duration in seconds in coarse human terms such as "3 hours".
+func DisplayDuration(seconds float64) string {
+	const (
+		minute  = 60.0
+		hour    = minute * 60
+		day     = hour * 24
+		month   = day * 31
+		year    = month * 12
+		century = year * 100
+	)
+
+	units := []struct {
+		size float64
+		name string
+	}{
+		{year, "year"},
+		{month, "month"},
+		{day, "day"},
+		{hour, "hour"},
+		{minute, "minute"},
+		{1, "second"},
+	}
+
+	switch {
+	case seconds < 1:
+		return "less than a second"
+	case seconds >= century:
+		return "centuries"
+	}
+	for _, unit := range units {
+		if seconds < unit.size {
+			continue
+		}
+		count := math.Round(seconds / unit.size)
+		if count == 1 {
+			return fmt.Sprintf("1 %s", unit.name)
+		}
+		return fmt.Sprintf("%.0f %ss", count, unit.name)
+	}
+	return "less than a second"
+}
@@ -0,0 +1,45 @@
+package password
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEstimateCrackTimes(t *testing.T) {
+	times := EstimateCrackTimes(1e10)
+	if len(times) != 4 {
+		t.Fatalf("expected four scenarios, got %d", len(times))
+	}
+	fast := times[len(times)-1]
+	if fast.Scenario != ScenarioOfflineFastHash || fast.Seconds != 1 || fast.Display != "1 second" {
+		t.Fatalf("unexpected fast hash estimate: %+v", fast)
+	}
+	if times[0].Display != "centuries" {
+		t.Fatalf("expected throttled online attack to take centuries, got %s", times[0].Display)
+	}
+}
+
+func TestEstimateCrackTimesStaysFinite(t *testing.T) {
+	for _, crackTime := range EstimateCrackTimes(math.MaxFloat64) {
+		if math.IsInf(crackTime.Seconds, 0) || crackTime.Seconds > math.MaxFloat64 {
+			t.Fatalf("expected a finite duration for %s, got %v", crackTime.Scenario, crackTime.Seconds)
+		}
+		if crackTime.Display != "centuries" {
+			t.Fatalf("expected centuries for %s, got %s", crackTime.Scenario, crackTime.Display)
+		}
+	}
+}
+
+func TestDisplayDuration(t *testing.T) {
+	cases := map[float64]string{
+		0.5:       "less than a second",
+		59:        "59 seconds",
+		3600 * 3:  "3 hours",
+		86400 * 2: "2 days",
+	}
+	for seconds, want := range cases {
+		if got := DisplayDuration(seconds); got != want {
+			t.Fatalf("DisplayDuration(%v) = %q, want %q", seconds, got, want)
+		}
+	}
+}
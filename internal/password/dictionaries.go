@@ -0,0 +1,134 @@
+package password
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Dictionary is a frequency-ranked word list: the first word is the most common. Words are
+// matched case-insensitively; umlauts and ß also match their ae, oe, ue and ss spellings.
+type Dictionary struct {
+	// Name identifies the list in matches, e.g. "de/cities"; the part before the slash is the
+	// language code.
+	Name  string
+	Words []string
+}
+
+//go:embed dictionaries
+var embeddedDictionaries embed.FS
+
+// builtinDictionaries are the embedded German and English lists of common words, first names,
+// surnames, football clubs and cities, in the order the files are named.
+var builtinDictionaries = func() []Dictionary {
+	dictionaries, err := readDictionaryDir(embeddedDictionaries, "dictionaries", "", true)
+	if err != nil {
+		panic(fmt.Sprintf("embedded dictionaries: %v", err))
+	}
+	return dictionaries
+}()
+
+// LoadDictionaries reads additional word lists with one word per line, most common first;
+// empty lines and lines starting with # are skipped. A file becomes a dictionary named after
+// the file, e.g. "fr.txt" becomes "fr". The .txt files of a directory are named after the
+// directory and the file, so fr/words.txt becomes "fr/words" whether the fr directory itself
+// or its parent, holding one directory per language, is given.
+func LoadDictionaries(location string) ([]Dictionary, error) {
+	info, err := os.Stat(location)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return readDictionaryDir(os.DirFS(location), ".", filepath.Base(filepath.Clean(location)), true)
+	}
+	file, err := os.Open(location)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	words, err := readWords(file)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", location, err)
+	}
+	name := strings.TrimSuffix(filepath.Base(location), filepath.Ext(location))
+	return []Dictionary{{Name: name, Words: words}}, nil
+}
+
+// readDictionaryDir reads the .txt files in dir, named language/file, in name order. With
+// languages set, subdirectories are read as well, each named after its directory.
+func readDictionaryDir(fsys fs.FS, dir, language string, languages bool) ([]Dictionary, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(a, b int) bool { return entries[a].Name() < entries[b].Name() })
+
+	var dictionaries []Dictionary
+	for _, entry := range entries {
+		name := path.Join(dir, entry.Name())
+		if entry.IsDir() {
+			if !languages {
+				continue
+			}
+			nested, err := readDictionaryDir(fsys, name, entry.Name(), false)
+			if err != nil {
+				return nil, err
+			}
+			dictionaries = append(dictionaries, nested...)
+			continue
+		}
+		if path.Ext(entry.Name()) != ".txt" {
+			continue
+		}
+		file, err := fsys.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		words, err := readWords(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", name, err)
+		}
+		dictionaryName := strings.TrimSuffix(entry.Name(), ".txt")
+		if language != "" {
+			dictionaryName = language + "/" + dictionaryName
+		}
+		dictionaries = append(dictionaries, Dictionary{Name: dictionaryName, Words: words})
+	}
+	return dictionaries, nil
+}
+
+func readWords(r io.Reader) ([]string, error) {
+	var words []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		words = append(words, Normalise(line))
+	}
+	return words, scanner.Err()
+}
+
+// umlautSpellings replaces umlauts and ß by the spelling used on keyboards without them.
+var umlautSpellings = strings.NewReplacer("ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss")
+
+// rankedDictionaries converts the dictionaries for the estimator.
+func rankedDictionaries(dictionaries []Dictionary) []rankedDictionary {
+	ranked := make([]rankedDictionary, 0, len(dictionaries))
+	for _, dictionary := range dictionaries {
+		ranked = append(ranked, newRankedDictionary(dictionary.Name, dictionary.Words))
+	}
+	return ranked
+}
+
+// builtinRankedDictionaries are built once and shared by all estimators.
+var builtinRankedDictionaries = rankedDictionaries(builtinDictionaries)
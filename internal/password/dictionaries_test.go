@@ -0,0 +1,130 @@
+package password
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// dictionaryMatch returns the match covering the whole password, if the estimate used one.
+func dictionaryMatch(t *testing.T, est *estimator, password string) Match {
+	t.Helper()
+	estimate := est.estimate(password)
+	if len(estimate.Sequence) != 1 || estimate.Sequence[0].Pattern != PatternDictionary {
+		t.Fatalf("%s: expected a single dictionary match, got %+v", password, estimate.Sequence)
+	}
+	return estimate.Sequence[0]
+}
+
+func TestBuiltinDictionariesRankWords(t *testing.T) {
+	est := newEstimator()
+	cases := []struct {
+		password   string
+		dictionary string
+	}{
+		{"Schmetterling", "de/words"},
+		{"Blumen", "de/words"},
+		{"Schoen", "de/words"},
+		{"schalke04", "de/football_clubs"},
+		{"Wuppertal", "de/cities"},
+		{"butterfly", "en/words"},
+		{"Jennifer", "en/first_names"},
+	}
+	for _, tc := range cases {
+		match := dictionaryMatch(t, est, tc.password)
+		if match.DictionaryName != tc.dictionary {
+			t.Fatalf("%s: expected a match in %s, got %+v", tc.password, tc.dictionary, match)
+		}
+	}
+
+	common := dictionaryMatch(t, est, "liebe")
+	rare := dictionaryMatch(t, est, "schmetterling")
+	if common.Guesses >= rare.Guesses {
+		t.Fatalf("expected the more frequent word to need fewer guesses, got %v and %v", common.Guesses, rare.Guesses)
+	}
+}
+
+func TestCompoundsAreSplitIntoWords(t *testing.T) {
+	est := newEstimator()
+	for password, parts := range map[string][]string{
+		"sommerhaus":        {"sommer", "haus"},
+		"Geburtstagskuchen": {"geburtstag", "kuchen"},
+		"sonnenblumen":      {"sonne", "blumen"},
+	} {
+		match := dictionaryMatch(t, est, password)
+		if len(match.Parts) != len(parts) || match.Parts[0] != parts[0] || match.Parts[1] != parts[1] || match.DictionaryName != "de/compounds" {
+			t.Fatalf("%s: expected compound of %v, got %+v", password, parts, match)
+		}
+	}
+}
+
+func TestLoadDictionariesAddsLanguages(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "fr"), 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "fr", "words.txt"), []byte("# mots fréquents\nbonjour\nchâteau\n"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	dictionaries, err := LoadDictionaries(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(dictionaries) != 1 || dictionaries[0].Name != "fr/words" || len(dictionaries[0].Words) != 2 {
+		t.Fatalf("expected fr/words with two words, got %+v", dictionaries)
+	}
+	if single, err := LoadDictionaries(filepath.Join(dir, "fr")); err != nil || len(single) != 1 || single[0].Name != "fr/words" {
+		t.Fatalf("expected the language directory to load as fr/words, got %+v, %v", single, err)
+	}
+
+	evaluator, err := NewEvaluator(Policy{MinLength: 8, Dictionaries: dictionaries})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	matches := evaluator.Assess("Château").Matches
+	if len(matches) != 1 || matches[0].DictionaryName != "fr/words" || matches[0].Rank != 2 {
+		t.Fatalf("expected château from fr/words, got %+v", matches)
+	}
+}
+
+func TestCompoundRanksSaturate(t *testing.T) {
+	rare := 1 << 20
+	dictionary := rankedDictionary{
+		name:      "de/words",
+		language:  "de",
+		ranks:     map[string]int{"sommer": rare, "haus": rare, "garten": rare, "tor": rare},
+		maxLength: 6,
+	}
+	est := &estimator{dictionaries: []rankedDictionary{dictionary}}
+	matches := est.compoundMatches([]rune("sommershausgartentor"))
+	var longest Match
+	for _, match := range matches {
+		if match.Rank < 1 {
+			t.Fatalf("expected a positive rank, got %+v", match)
+		}
+		if len(match.Parts) > len(longest.Parts) {
+			longest = match
+		}
+	}
+	if len(longest.Parts) != 4 || longest.Rank != math.MaxInt {
+		t.Fatalf("expected the four-part compound to saturate, got %+v", longest)
+	}
+}
+
+func TestCompoundLimitAppliesPerStart(t *testing.T) {
+	words := []string{"sommer", "haus"}
+	for length := minCompoundPartLength; length <= 8; length++ {
+		words = append(words, strings.Repeat("a", length))
+	}
+	est := &estimator{dictionaries: []rankedDictionary{newRankedDictionary("de/words", words)}}
+	password := strings.Repeat("a", 40) + "sommerhaus"
+	for _, match := range est.compoundMatches([]rune(password)) {
+		if match.I == 40 && match.MatchedWord == "sommerhaus" {
+			return
+		}
+	}
+	t.Fatal("expected the compound after the overlapping words to be found")
+}
@@ -0,0 +1,453 @@
+package password
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Pattern names the kind of structure a Match explains.
+type Pattern string
+
+const (
+	PatternDictionary Pattern = "dictionary"
+	PatternSpatial    Pattern = "spatial"
+	PatternSequence   Pattern = "sequence"
+	PatternRepeat     Pattern = "repeat"
+	PatternDate       Pattern = "date"
+	PatternBruteforce Pattern = "bruteforce"
+	// PatternPassphraseWord is a word of a passphrase, guessed as a pick from the word list.
+	PatternPassphraseWord Pattern = "passphrase_word"
+)
+
+const (
+	// bruteforceCardinality approximates the per-character search space of a bruteforce attacker.
+	bruteforceCardinality = 10
+	// minGuessesBeforeGrowingSequence penalises decompositions made of many small matches.
+	minGuessesBeforeGrowingSequence = 10000
+	minSubmatchGuessesSingleChar    = 10
+	minSubmatchGuessesMultiChar     = 50
+	minYearSpace                    = 20
+	// maxEstimateLength bounds the quadratic matching work; longer tails only run the cheap matchers.
+	maxEstimateLength = 256
+)
+
+// userInputsDictionary names the dictionary built from the evaluation context terms.
+const userInputsDictionary = "user_inputs"
+
+// referenceYear anchors year-based guess estimates to the present.
+var referenceYear = time.Now().Year()
+
+// Match is a segment of the password explained by a single pattern. Offsets I and J
+// are inclusive rune indices. Only the fields relevant to the Pattern are populated.
+type Match struct {
+	Pattern Pattern
+	I, J    int
+	Token   string
+	Guesses float64
+
+	// Dictionary matches.
+	MatchedWord    string
+	Rank           int
+	DictionaryName string
+	Reversed       bool
+	L33t           bool
+	L33tSub        map[rune]rune
+
+	// Spatial matches.
+	Graph        string
+	Turns        int
+	Repeats      int
+	ShiftedCount int
+
+	// Sequence matches.
+	SequenceName string
+	Ascending    bool
+
+	// Repeat matches.
+	BaseToken   string
+	BaseGuesses float64
+	RepeatCount int
+
+	// Date matches.
+	Year, Month, Day int
+	Separator        string
+
+	// Parts lists the words of a compound dictionary match, e.g. "sommer" and "haus".
+	Parts []string
+	// ManglingRule is the hashcat rule that turned MatchedWord into the token, e.g. "c $1".
+	ManglingRule string
+}
+
+// Estimate is the minimum-guess decomposition of a password.
+type Estimate struct {
+	Guesses      float64
+	GuessesLog10 float64
+	EntropyBits  float64
+	Sequence     []Match
+	// matches are all matches found before the cheapest sequence was chosen; the built-in
+	// rules report their findings from them instead of running the matchers again.
+	matches []Match
+}
+
+// estimator finds pattern matches in a password and scores the cheapest way to guess it.
+type estimator struct {
+	dictionaries []rankedDictionary
+	graphs       []keyboardGraph
+}
+
+func newEstimator() *estimator {
+	return &estimator{
+		dictionaries: append([]rankedDictionary{commonPasswordDictionary}, builtinRankedDictionaries...),
+		graphs:       defaultKeyboardGraphs(),
+	}
+}
+
+// withUserInputs returns an estimator that also knows the context terms as a dictionary,
+// ranked in the order they were supplied.
+func (e *estimator) withUserInputs(terms []contextTerm) *estimator {
+	if len(terms) == 0 {
+		return e
+	}
+	words := make([]string, 0, len(terms))
+	for _, term := range terms {
+		words = append(words, term.term)
+	}
+	extended := *e
+	extended.dictionaries = append(append([]rankedDictionary(nil), e.dictionaries...), newRankedDictionary(userInputsDictionary, words))
+	return &extended
+}
+
+// estimate returns the minimum number of guesses an attacker needs, following the
+// zxcvbn approach of searching for the cheapest sequence of non-overlapping matches.
+// Characters beyond maxEstimateLength are scored by tailSequence.
+func (e *estimator) estimate(password string) Estimate {
+	all := []rune(password)
+	runes := all
+	if len(all) > maxEstimateLength {
+		runes = all[:maxEstimateLength]
+	}
+
+	matches := e.omnimatch(runes)
+	guesses, sequence := e.mostGuessableSequence(runes, matches)
+	if len(all) > len(runes) {
+		tailGuesses, tail := e.tailSequence(all, len(runes))
+		guesses *= tailGuesses
+		sequence = append(sequence, tail...)
+	}
+	if math.IsInf(guesses, 1) {
+		guesses = math.MaxFloat64
+	}
+
+	return Estimate{
+		Guesses:      guesses,
+		GuessesLog10: math.Log10(guesses),
+		EntropyBits:  math.Log2(guesses),
+		Sequence:     sequence,
+		matches:      matches,
+	}
+}
+
+// tailSequence scores the runes from start on, which are too many for the full search. Only
+// the cheap sequence and repeat matchers run there, and the cheapest split into those matches
+// and single characters is kept. A character counts as bruteforce over the distinct characters
+// of the tail, at most bruteforceCardinality, so padding a password with a few characters
+// repeated over and over adds next to nothing.
+func (e *estimator) tailSequence(all []rune, start int) (float64, []Match) {
+	tail := all[start:]
+	n := len(tail)
+	distinct := make(map[rune]struct{}, bruteforceCardinality)
+	for _, r := range tail {
+		distinct[r] = struct{}{}
+	}
+	cardinality := math.Min(float64(len(distinct)), bruteforceCardinality)
+
+	matchesByEnd := make([][]Match, n)
+	for _, match := range sequenceMatches(tail) {
+		match.Guesses = e.guessesFor(&match, len(all))
+		match.I += start
+		match.J += start
+		matchesByEnd[match.J-start] = append(matchesByEnd[match.J-start], match)
+	}
+	// Repeats are found across the whole password, so a repeat begun before start only
+	// costs its extra repetitions in the tail.
+	for _, match := range e.repeatMatches(all) {
+		if match.J < start {
+			continue
+		}
+		if match.I < start {
+			baseLength := len([]rune(match.BaseToken))
+			match.I = start
+			match.Token = string(all[start : match.J+1])
+			match.RepeatCount = (match.J - start + baseLength) / baseLength
+			match.Guesses = math.Max(float64(match.RepeatCount), minSubmatchGuessesMultiChar)
+		} else {
+			match.Guesses = e.guessesFor(&match, len(all))
+		}
+		matchesByEnd[match.J-start] = append(matchesByEnd[match.J-start], match)
+	}
+
+	// best[k] is the fewest guesses for the first k runes of the tail; last[k] is the match
+	// ending there, or a zero Match when rune k-1 is guessed on its own.
+	best := make([]float64, n+1)
+	last := make([]Match, n+1)
+	best[0] = 1
+	for k := 1; k <= n; k++ {
+		best[k] = best[k-1] * cardinality
+		for _, match := range matchesByEnd[k-1] {
+			if guesses := best[match.I-start] * match.Guesses; guesses < best[k] {
+				best[k], last[k] = guesses, match
+			}
+		}
+	}
+
+	var sequence []Match
+	for k := n; k > 0; {
+		if last[k].Pattern != "" {
+			sequence = append(sequence, last[k])
+			k = last[k].I - start
+			continue
+		}
+		i := k - 1
+		for i > 0 && last[i].Pattern == "" {
+			i--
+		}
+		run := bruteforceMatch(all, start+i, start+k-1)
+		run.Guesses = math.Min(math.Pow(cardinality, float64(k-i)), math.MaxFloat64)
+		sequence = append(sequence, run)
+		k = i
+	}
+	for left, right := 0, len(sequence)-1; left < right; left, right = left+1, right-1 {
+		sequence[left], sequence[right] = sequence[right], sequence[left]
+	}
+	return best[n], sequence
+}
+
+func (e *estimator) omnimatch(runes []rune) []Match {
+	var matches []Match
+	matches = append(matches, e.dictionaryMatches(runes)...)
+	matches = append(matches, e.reverseDictionaryMatches(runes)...)
+	matches = append(matches, e.l33tMatches(runes)...)
+	matches = append(matches, e.compoundMatches(runes)...)
+	if match, ok := e.mangledMatch(runes); ok {
+		matches = append(matches, match)
+	}
+	matches = append(matches, e.spatialMatches(runes)...)
+	matches = append(matches, sequenceMatches(runes)...)
+	matches = append(matches, e.repeatMatches(runes)...)
+	matches = append(matches, e.dateMatches(runes)...)
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].I != matches[b].I {
+			return matches[a].I < matches[b].I
+		}
+		return matches[a].J < matches[b].J
+	})
+	return matches
+}
+
+// mostGuessableSequence runs the zxcvbn dynamic programme: for every prefix length and
+// every number of matches l it keeps the cheapest sequence, then unwinds the optimum.
+func (e *estimator) mostGuessableSequence(runes []rune, matches []Match) (float64, []Match) {
+	n := len(runes)
+	if n == 0 {
+		return 1, nil
+	}
+
+	matchesByEnd := make([][]Match, n)
+	for _, match := range matches {
+		matchesByEnd[match.J] = append(matchesByEnd[match.J], match)
+	}
+
+	// optimalMatch[k][l] is the last match of the best l-match sequence covering runes[0..k].
+	optimalMatch := make([]map[int]Match, n)
+	optimalProduct := make([]map[int]float64, n)
+	optimalGuesses := make([]map[int]float64, n)
+	for k := 0; k < n; k++ {
+		optimalMatch[k] = map[int]Match{}
+		optimalProduct[k] = map[int]float64{}
+		optimalGuesses[k] = map[int]float64{}
+	}
+
+	update := func(match Match, l int) {
+		k := match.J
+		product := e.guessesFor(&match, n)
+		if l > 1 {
+			product *= optimalProduct[match.I-1][l-1]
+		}
+		guesses := factorial(l)*product + math.Pow(minGuessesBeforeGrowingSequence, float64(l-1))
+		for competingL, competingGuesses := range optimalGuesses[k] {
+			if competingL > l {
+				continue
+			}
+			if competingGuesses <= guesses {
+				return
+			}
+		}
+		optimalGuesses[k][l] = guesses
+		optimalMatch[k][l] = match
+		optimalProduct[k][l] = product
+	}
+
+	bruteforceUpdate := func(k int) {
+		update(bruteforceMatch(runes, 0, k), 1)
+		for i := 1; i <= k; i++ {
+			match := bruteforceMatch(runes, i, k)
+			for l, last := range optimalMatch[i-1] {
+				// Adjacent bruteforce matches are never better than one longer bruteforce match.
+				if last.Pattern == PatternBruteforce {
+					continue
+				}
+				update(match, l+1)
+			}
+		}
+	}
+
+	for k := 0; k < n; k++ {
+		for _, match := range matchesByEnd[k] {
+			if match.I > 0 {
+				for l := range optimalMatch[match.I-1] {
+					update(match, l+1)
+				}
+			} else {
+				update(match, 1)
+			}
+		}
+		bruteforceUpdate(k)
+	}
+
+	bestL := 0
+	bestGuesses := math.Inf(1)
+	for l, guesses := range optimalGuesses[n-1] {
+		if guesses < bestGuesses || (guesses == bestGuesses && l < bestL) {
+			bestL, bestGuesses = l, guesses
+		}
+	}
+
+	sequence := make([]Match, 0, bestL)
+	for k, l := n-1, bestL; k >= 0 && l > 0; l-- {
+		match := optimalMatch[k][l]
+		sequence = append(sequence, match)
+		k = match.I - 1
+	}
+	for left, right := 0, len(sequence)-1; left < right; left, right = left+1, right-1 {
+		sequence[left], sequence[right] = sequence[right], sequence[left]
+	}
+	return bestGuesses, sequence
+}
+
+// longestNonOverlapping keeps the longest matches that do not overlap a longer one,
+// ordered by position. It selects the segments worth reporting as findings.
+func longestNonOverlapping(candidates []Match) []Match {
+	ordered := append([]Match(nil), candidates...)
+	sort.SliceStable(ordered, func(a, b int) bool {
+		return ordered[a].J-ordered[a].I > ordered[b].J-ordered[b].I
+	})
+
+	var selected []Match
+	covered := map[int]bool{}
+	for _, candidate := range ordered {
+		overlaps := false
+		for idx := candidate.I; idx <= candidate.J; idx++ {
+			if covered[idx] {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			continue
+		}
+		for idx := candidate.I; idx <= candidate.J; idx++ {
+			covered[idx] = true
+		}
+		selected = append(selected, candidate)
+	}
+	sort.Slice(selected, func(a, b int) bool { return selected[a].I < selected[b].I })
+	return selected
+}
+
+func bruteforceMatch(runes []rune, i, j int) Match {
+	return Match{Pattern: PatternBruteforce, I: i, J: j, Token: string(runes[i : j+1])}
+}
+
+// guessesFor computes and caches the guess estimate of a match within a password of length n.
+func (e *estimator) guessesFor(match *Match, n int) float64 {
+	if match.Guesses > 0 {
+		return match.Guesses
+	}
+
+	tokenLength := match.J - match.I + 1
+	minGuesses := 1.0
+	if tokenLength < n {
+		minGuesses = minSubmatchGuessesMultiChar
+		if tokenLength == 1 {
+			minGuesses = minSubmatchGuessesSingleChar
+		}
+	}
+
+	var guesses float64
+	switch match.Pattern {
+	case PatternBruteforce:
+		guesses = bruteforceGuesses(match)
+	case PatternDictionary:
+		guesses = dictionaryGuesses(match)
+	case PatternSpatial:
+		guesses = e.spatialGuesses(match)
+	case PatternRepeat:
+		guesses = match.BaseGuesses * float64(match.RepeatCount)
+	case PatternSequence:
+		guesses = sequenceGuesses(match)
+	case PatternDate:
+		guesses = dateGuesses(match)
+	}
+	match.Guesses = math.Max(guesses, minGuesses)
+	return match.Guesses
+}
+
+func bruteforceGuesses(match *Match) float64 {
+	length := len([]rune(match.Token))
+	guesses := math.Pow(bruteforceCardinality, float64(length))
+	if math.IsInf(guesses, 1) {
+		guesses = math.MaxFloat64
+	}
+	minimum := float64(minSubmatchGuessesMultiChar + 1)
+	if length == 1 {
+		minimum = minSubmatchGuessesSingleChar + 1
+	}
+	return math.Max(guesses, minimum)
+}
+
+func yearSpace(year int) float64 {
+	space := year - referenceYear
+	if space < 0 {
+		space = -space
+	}
+	if space < minYearSpace {
+		space = minYearSpace
+	}
+	return float64(space)
+}
+
+func factorial(n int) float64 {
+	result := 1.0
+	for i := 2; i <= n; i++ {
+		result *= float64(i)
+	}
+	return result
+}
+
+// binomial returns n choose k as a float to avoid overflow for long tokens.
+func binomial(n, k int) float64 {
+	if k > n || k < 0 {
+		return 0
+	}
+	if k == 0 {
+		return 1
+	}
+	result := 1.0
+	for d := 1; d <= k; d++ {
+		result *= float64(n)
+		result /= float64(d)
+		n--
+	}
+	return result
+}
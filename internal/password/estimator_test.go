@@ -0,0 +1,308 @@
+package password
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEstimatorDecomposesPatterns(t *testing.T) {
+	est := newEstimator()
+	cases := []struct {
+		password string
+		pattern  Pattern
+		token    string
+	}{
+		{"P@ssw0rd", PatternDictionary, "P@ssw0rd"},
+		{"drowssap", PatternDictionary, "drowssap"},
+		{"zxcvbnm,./", PatternSpatial, "zxcvbnm,./"},
+		{"xK9abcdefg", PatternSequence, "abcdefg"},
+		{"Zq!aaaaaaa", PatternRepeat, "aaaaaaa"},
+		{"Q!x31121999", PatternDate, "31121999"},
+	}
+	for _, tc := range cases {
+		estimate := est.estimate(tc.password)
+		found := false
+		for _, match := range estimate.Sequence {
+			if match.Pattern == tc.pattern && match.Token == tc.token {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("%s: expected %s match for %q, got %+v", tc.password, tc.pattern, tc.token, estimate.Sequence)
+		}
+	}
+}
+
+func TestEstimatorSequenceCoversPassword(t *testing.T) {
+	estimate := newEstimator().estimate("xQ7qwerty2024!")
+	next := 0
+	for _, match := range estimate.Sequence {
+		if match.I != next {
+			t.Fatalf("expected match to start at %d, got %+v", next, match)
+		}
+		next = match.J + 1
+	}
+	if next != len([]rune("xQ7qwerty2024!")) {
+		t.Fatalf("sequence does not cover the whole password")
+	}
+}
+
+func TestEstimatorL33tMatchRecordsSubstitution(t *testing.T) {
+	estimate := newEstimator().estimate("P@ssw0rd")
+	match := estimate.Sequence[0]
+	if !match.L33t || match.MatchedWord != "password" || match.L33tSub['@'] != 'a' || match.L33tSub['0'] != 'o' {
+		t.Fatalf("unexpected l33t match: %+v", match)
+	}
+}
+
+func TestAssessBasesStrengthOnGuesses(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 12})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assessment := evaluator.Assess("Password2024!")
+	if assessment.Strength == StrengthStrong {
+		t.Fatalf("expected Password2024! not to be strong (log10 guesses %.2f)", assessment.GuessesLog10)
+	}
+	if assessment.EntropyBits <= 0 || assessment.GuessesLog10 <= 0 {
+		t.Fatalf("expected positive entropy, got %+v", assessment)
+	}
+
+	random := evaluator.Assess("kL9#vQ2!xZ7@wR")
+	if random.Strength != StrengthStrong {
+		t.Fatalf("expected random password to be strong, got %s (log10 %.2f)", random.Strength, random.GuessesLog10)
+	}
+	if random.GuessesLog10 <= assessment.GuessesLog10 {
+		t.Fatalf("expected random password to need more guesses")
+	}
+}
+
+func TestKeyboardWalksAcrossLayouts(t *testing.T) {
+	est := newEstimator()
+	cases := map[string]string{
+		"qwertz123":  "qwertz",
+		"yxcvbnm":    "qwertz",
+		"Xazerty9":   "azerty",
+		"7412369!":   "numpad",
+		"!QAZ2wsx":   "qwerty",
+		"asddfghjk4": "qwerty",
+	}
+	for password, layout := range cases {
+		walks := keyboardWalks(est.omnimatch([]rune(password)))
+		found := false
+		for _, walk := range walks {
+			if walk.Graph == layout {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("%s: expected a walk on %s, got %+v", password, layout, walks)
+		}
+	}
+}
+
+func TestKeyboardWalkFindingNamesPosition(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 8, KeyboardLayouts: []string{"qwertz"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assessment := evaluator.Assess("Xk7%qwertz123")
+	for _, finding := range assessment.Findings {
+		if finding.Code == "pattern.keyboard" {
+			if finding.Message != `keyboard walk "qwertz" on the qwertz layout at positions 5-10` {
+				t.Fatalf("unexpected message: %s", finding.Message)
+			}
+			return
+		}
+	}
+	t.Fatalf("expected pattern.keyboard finding, got %+v", assessment.Findings)
+}
+
+func TestKeyboardWalksIgnoreSequences(t *testing.T) {
+	for _, password := range []string{"Zk1234", "Zk!2024"} {
+		if walks := keyboardWalks(newEstimator().omnimatch([]rune(password))); len(walks) != 0 {
+			t.Fatalf("%s: expected no keyboard walk, got %+v", password, walks)
+		}
+	}
+}
+
+func TestDateMatchesConventions(t *testing.T) {
+	cases := []struct {
+		password         string
+		token            string
+		day, month, year int
+	}{
+		{"31.12.1999", "31.12.1999", 31, 12, 1999},
+		{"x1.1.99!", "1.1.99", 1, 1, 1999},
+		{"Q!1999-12-31", "1999-12-31", 31, 12, 1999},
+		{"x1999-05-12y", "1999-05-12", 12, 5, 1999},
+		{"x05.12.1999y", "05.12.1999", 5, 12, 1999},
+		{"Q!19990512", "19990512", 12, 5, 1999},
+		{"ab12/31/1985", "12/31/1985", 31, 12, 1985},
+		{"ab03/04/1985", "03/04/1985", 4, 3, 1985},
+		{"Anna1987!", "1987", 0, 0, 1987},
+		{"Anna87!", "87", 0, 0, 1987},
+		{"Weihnachten24.12.", "24.12.", 24, 12, 0},
+	}
+	for _, tc := range cases {
+		dates := longestNonOverlapping(newEstimator().dateMatches([]rune(tc.password)))
+		if len(dates) != 1 {
+			t.Fatalf("%s: expected one date, got %+v", tc.password, dates)
+		}
+		date := dates[0]
+		if date.Token != tc.token || date.Day != tc.day || date.Month != tc.month || date.Year != tc.year {
+			t.Fatalf("%s: unexpected date %+v", tc.password, date)
+		}
+	}
+}
+
+func TestTwoDigitYearsNeedAWord(t *testing.T) {
+	est := newEstimator()
+	for _, password := range []string{"xQvRk87#", "Tz87!pLm"} {
+		if dates := est.dateMatches([]rune(password)); len(dates) != 0 {
+			t.Fatalf("%s: expected no date in random characters, got %+v", password, dates)
+		}
+	}
+	if dates := est.dateMatches([]rune("Sommer99!")); len(dates) != 1 || dates[0].Year != 1999 {
+		t.Fatalf("expected a year after a word, got %+v", dates)
+	}
+}
+
+func TestDatesHideSequencesAndRepeatsInside(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, password := range []string{"Anna1987!", "31.12.1999"} {
+		for _, finding := range evaluator.Assess(password).Findings {
+			if finding.Code == "pattern.sequence" || finding.Code == "pattern.repeat" {
+				t.Fatalf("%s: expected no finding inside the date, got %+v", password, finding)
+			}
+		}
+	}
+}
+
+func TestDatePasswordsAreNotStrong(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, password := range []string{"31.12.1999", "Anna1987!", "1985-07-14"} {
+		assessment := evaluator.Assess(password)
+		if assessment.Strength == StrengthStrong {
+			t.Fatalf("%s: expected date-based password not to be strong", password)
+		}
+		found := false
+		for _, finding := range assessment.Findings {
+			if finding.Code == "pattern.date" {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("%s: expected pattern.date finding, got %+v", password, assessment.Findings)
+		}
+	}
+}
+
+func TestDateGuessesAreCapped(t *testing.T) {
+	match := Match{Pattern: PatternDate, Token: "01.01.1100", Day: 1, Month: 1, Year: 1100, Separator: "."}
+	if guesses := dateGuesses(&match); guesses > maxDateGuesses {
+		t.Fatalf("expected date guesses to be capped, got %v", guesses)
+	}
+}
+
+func TestRepeatMatchesSubstrings(t *testing.T) {
+	cases := []struct {
+		password string
+		token    string
+		base     string
+		count    int
+	}{
+		{"aaaaaa", "aaaaaa", "a", 6},
+		{"abcabcabc", "abcabcabc", "abc", 3},
+		{"X!abab", "abab", "ab", 2},
+		{"hallohallo1", "hallohallo", "hallo", 2},
+	}
+	est := newEstimator()
+	for _, tc := range cases {
+		matches := est.repeatMatches([]rune(tc.password))
+		if len(matches) != 1 {
+			t.Fatalf("%s: expected one repeat, got %+v", tc.password, matches)
+		}
+		match := matches[0]
+		if match.Token != tc.token || match.BaseToken != tc.base || match.RepeatCount != tc.count {
+			t.Fatalf("%s: unexpected repeat %+v", tc.password, match)
+		}
+	}
+}
+
+func TestSequenceMatchesUnicodeBlocks(t *testing.T) {
+	for _, password := range []string{"αβγδ", "ЯЮЭЬ", "987654321"} {
+		matches := sequenceMatches([]rune(password))
+		if len(matches) != 1 || matches[0].Token != password {
+			t.Fatalf("%s: expected the whole password as sequence, got %+v", password, matches)
+		}
+	}
+	if matches := sequenceMatches([]rune("aβc")); len(matches) != 0 {
+		t.Fatalf("expected no sequence across scripts, got %+v", matches)
+	}
+}
+
+func TestPatternFindingsReportOffsets(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assessment := evaluator.Assess("Zq!abcdefxyxyxy")
+	want := map[string]string{
+		"pattern.sequence": `ascending sequence "abcdef" at positions 4-9 is easy to guess`,
+		"pattern.repeat":   `"xyxyxy" repeats "xy" 3 times at positions 10-15`,
+	}
+	for _, finding := range assessment.Findings {
+		if message, ok := want[finding.Code]; ok {
+			if finding.Message != message {
+				t.Fatalf("unexpected %s message: %s", finding.Code, finding.Message)
+			}
+			delete(want, finding.Code)
+		}
+	}
+	if len(want) > 0 {
+		t.Fatalf("missing findings %v in %+v", want, assessment.Findings)
+	}
+	if assessment.Strength == StrengthStrong {
+		t.Fatalf("expected sequences and repeats to be discounted")
+	}
+}
+
+func TestLongRepeatedPasswordsStayWeak(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, password := range []string{strings.Repeat("a", 300), strings.Repeat("a1", 2000), strings.Repeat("abcdef", 100)} {
+		assessment := evaluator.Assess(password)
+		if assessment.Strength == StrengthStrong || assessment.GuessesLog10 > 10 {
+			t.Fatalf("%d runes: expected padding not to make the password strong, got %v at 10^%.1f", len(password), assessment.Strength, assessment.GuessesLog10)
+		}
+	}
+}
+
+func TestLongRandomPasswordsEstimateQuickly(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	runes := make([]rune, 20000)
+	for i := range runes {
+		runes[i] = rune('!' + random.Intn(94))
+	}
+	started := time.Now()
+	estimate := newEstimator().estimate(string(runes))
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Fatalf("expected a long password to be estimated quickly, took %v", elapsed)
+	}
+	if estimate.Guesses != math.MaxFloat64 {
+		t.Fatalf("expected a long random password to reach the guess cap, got %v", estimate.Guesses)
+	}
+}
@@ -0,0 +1,162 @@
+package password
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// maskRune replaces the characters outside a segment when it is shown.
+const maskRune = '*'
+
+// Segment explains one part of the password: the pattern an attacker would use to guess it
+// and how much that part contributes to the estimated guesses.
+type Segment struct {
+	Pattern Pattern `json:"pattern"`
+	// Start and End are 1-based, inclusive character positions.
+	Start int `json:"start"`
+	End   int `json:"end"`
+	// Masked is the password with every character outside the segment masked.
+	Masked       string  `json:"masked"`
+	GuessesLog10 float64 `json:"guesses_log10"`
+	// Share is the segment's fraction of the summed guess exponents of all segments.
+	Share  float64 `json:"share"`
+	Detail string  `json:"detail,omitempty"`
+	// Findings lists the codes of the findings the segment triggered.
+	Findings []string `json:"findings,omitempty"`
+}
+
+// Segments turns the matches of an assessment into segments in password order and links
+// each segment to the findings its pattern triggered.
+func Segments(matches []Match, findings []Finding) []Segment {
+	var runes []rune
+	total := 0.0
+	for _, match := range matches {
+		runes = append(runes, []rune(match.Token)...)
+		total += math.Log10(math.Max(match.Guesses, 1))
+	}
+
+	segments := make([]Segment, 0, len(matches))
+	for _, match := range matches {
+		masked := make([]rune, len(runes))
+		for i, r := range runes {
+			masked[i] = maskRune
+			if i >= match.I && i <= match.J {
+				masked[i] = r
+			}
+		}
+		guessesLog10 := math.Log10(math.Max(match.Guesses, 1))
+		share := 0.0
+		if total > 0 {
+			share = guessesLog10 / total
+		}
+		segments = append(segments, Segment{
+			Pattern:      match.Pattern,
+			Start:        match.I + 1,
+			End:          match.J + 1,
+			Masked:       string(masked),
+			GuessesLog10: guessesLog10,
+			Share:        share,
+			Detail:       matchDetail(match),
+			Findings:     segmentFindings(match, findings),
+		})
+	}
+	return segments
+}
+
+// matchDetail describes what the pattern of the match recognised.
+func matchDetail(match Match) string {
+	switch match.Pattern {
+	case PatternDictionary:
+		detail := fmt.Sprintf("word %q ranked %d in %s", match.MatchedWord, match.Rank, match.DictionaryName)
+		switch {
+		case len(match.Parts) > 1:
+			detail = fmt.Sprintf("compound %q of the words %s", match.MatchedWord, quoteJoin(match.Parts))
+		case match.ManglingRule != "":
+			detail = fmt.Sprintf("word %q from %s with the rule %q", match.MatchedWord, match.DictionaryName, match.ManglingRule)
+		}
+		var transformations []string
+		if match.L33t {
+			transformations = append(transformations, TransformationL33t)
+		}
+		if match.Reversed {
+			transformations = append(transformations, TransformationReversed)
+		}
+		if len(transformations) > 0 {
+			detail += " (" + strings.Join(transformations, ", ") + ")"
+		}
+		return detail
+	case PatternSpatial:
+		return fmt.Sprintf("keyboard walk on the %s layout with %d turn(s)", match.Graph, match.Turns)
+	case PatternSequence:
+		direction := "descending"
+		if match.Ascending {
+			direction = "ascending"
+		}
+		return fmt.Sprintf("%s %s sequence", direction, match.SequenceName)
+	case PatternRepeat:
+		return fmt.Sprintf("%q repeated %d times", match.BaseToken, match.RepeatCount)
+	case PatternDate:
+		switch {
+		case match.Month == 0:
+			return fmt.Sprintf("year %d", match.Year)
+		case match.Year == 0:
+			return fmt.Sprintf("day %02d.%02d.", match.Day, match.Month)
+		default:
+			return fmt.Sprintf("date %04d-%02d-%02d", match.Year, match.Month, match.Day)
+		}
+	case PatternPassphraseWord:
+		if match.Rank > 0 {
+			return fmt.Sprintf("one of %d words in the word list", match.Rank)
+		}
+		return "word guessed by its own patterns"
+	default:
+		return "random characters"
+	}
+}
+
+// patternFindingCodes maps patterns to the code of the finding their rule reports.
+var patternFindingCodes = map[Pattern]string{
+	PatternSpatial:  "pattern.keyboard",
+	PatternSequence: "pattern.sequence",
+	PatternRepeat:   "pattern.repeat",
+	PatternDate:     "pattern.date",
+}
+
+// segmentFindings returns the codes of the findings caused by the match, each once. Findings
+// about a part of the password count for the segments of the same pattern overlapping that
+// part. Dictionary matches trigger the common password finding, the context finding naming
+// the matched term or, for mangled words, the mangling finding.
+func segmentFindings(match Match, findings []Finding) []string {
+	var codes []string
+	seen := make(map[string]bool)
+	for _, finding := range findings {
+		var triggered bool
+		switch match.Pattern {
+		case PatternDictionary:
+			switch {
+			case match.ManglingRule != "":
+				triggered = finding.Code == "password.mangled"
+			case match.DictionaryName == commonPasswordDictionary.name:
+				triggered = finding.Code == "password.common"
+			case match.DictionaryName == userInputsDictionary:
+				triggered = strings.HasPrefix(finding.Code, "context.") && strings.Contains(finding.Message, fmt.Sprintf("%q", match.MatchedWord))
+			}
+		default:
+			triggered = finding.Code == patternFindingCodes[match.Pattern] && (!finding.located || (finding.i <= match.J && match.I <= finding.j))
+		}
+		if triggered && !seen[finding.Code] {
+			seen[finding.Code] = true
+			codes = append(codes, finding.Code)
+		}
+	}
+	return codes
+}
+
+func quoteJoin(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = fmt.Sprintf("%q", value)
+	}
+	return strings.Join(quoted, " + ")
+}
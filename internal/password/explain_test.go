@@ -0,0 +1,104 @@
+package password
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestSegmentsExplainTheEstimate(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assessment := evaluator.Assess("2024password")
+
+	segments := Segments(assessment.Matches, assessment.Findings)
+	if len(segments) != 2 {
+		t.Fatalf("expected a date and a word segment, got %+v", segments)
+	}
+	date, word := segments[0], segments[1]
+	if word.Pattern != PatternDictionary || word.Start != 5 || word.End != 12 || word.Masked != "****password" || len(word.Findings) != 1 || word.Findings[0] != "password.common" {
+		t.Fatalf("unexpected word segment %+v", word)
+	}
+	if date.Pattern != PatternDate || date.Start != 1 || date.End != 4 || date.Masked != "2024********" || date.Detail != "year 2024" {
+		t.Fatalf("unexpected date segment %+v", date)
+	}
+	if len(date.Findings) != 1 || date.Findings[0] != "pattern.date" {
+		t.Fatalf("expected the date segment to trigger pattern.date, got %v", date.Findings)
+	}
+	if math.Abs(word.Share+date.Share-1) > 1e-9 {
+		t.Fatalf("expected shares to add up to 1, got %v and %v", word.Share, date.Share)
+	}
+}
+
+func TestSegmentsCoverTheTruncatedTail(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	password := strings.Repeat("a", maxEstimateLength) + "Xk7#qZw2!pL9"
+	assessment := evaluator.Assess(password)
+
+	segments := Segments(assessment.Matches, assessment.Findings)
+	last := segments[len(segments)-1]
+	if last.Pattern != PatternBruteforce || last.Start != maxEstimateLength+1 || last.End != len(password) {
+		t.Fatalf("expected a bruteforce segment for the tail, got %+v", last)
+	}
+	if got := len([]rune(last.Masked)); got != len(password) || !strings.HasSuffix(last.Masked, "Xk7#qZw2!pL9") {
+		t.Fatalf("expected the tail unmasked in the full password, got %q", last.Masked)
+	}
+	if math.Abs(last.GuessesLog10-12) > 1e-9 {
+		t.Fatalf("expected the tail to need 10^12 guesses, got 10^%v", last.GuessesLog10)
+	}
+}
+
+func TestSegmentFindingsFollowPositions(t *testing.T) {
+	matches := []Match{
+		{Pattern: PatternSpatial, I: 0, J: 5, Token: "qwerty", Guesses: 1e3},
+		{Pattern: PatternBruteforce, I: 6, J: 9, Token: "Xk7#", Guesses: 1e4},
+		{Pattern: PatternSpatial, I: 10, J: 15, Token: "asdfgh", Guesses: 1e3},
+	}
+	walk := Finding{Code: "pattern.keyboard", Severity: SeverityWarn}
+	both := Segments(matches, []Finding{walk.at(0, 5), walk.at(10, 15), walk.at(1, 4)})
+	for _, idx := range []int{0, 2} {
+		if codes := both[idx].Findings; len(codes) != 1 || codes[0] != "pattern.keyboard" {
+			t.Fatalf("segment %d: expected pattern.keyboard once, got %v", idx, codes)
+		}
+	}
+	first := Segments(matches, []Finding{walk.at(0, 5)})
+	if len(first[2].Findings) != 0 {
+		t.Fatalf("expected the second walk not to take the first walk's finding, got %v", first[2].Findings)
+	}
+}
+
+func TestPassphraseSegmentsFollowTheWords(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	password := "correct horse battery staple fish"
+	assessment := evaluator.Assess(password)
+	if assessment.Mode != ModePassphrase {
+		t.Fatalf("expected a passphrase, got %s", assessment.Mode)
+	}
+
+	segments := Segments(assessment.Matches, assessment.Findings)
+	if len(segments) != 5 {
+		t.Fatalf("expected one segment per word, got %+v", segments)
+	}
+	total := 0.0
+	for idx, word := range strings.Fields(password) {
+		segment := segments[idx]
+		if token := strings.TrimSpace(string([]rune(password)[segment.Start-1 : segment.End])); token != word {
+			t.Fatalf("expected segment %d to cover %q, got %q", idx, word, token)
+		}
+		total += segment.GuessesLog10
+	}
+	if segments[0].Pattern != PatternPassphraseWord || segments[1].Pattern != PatternDictionary {
+		t.Fatalf("expected list and dictionary words, got %+v", segments)
+	}
+	if math.Abs(total-assessment.GuessesLog10) > 1e-9 {
+		t.Fatalf("expected the segments to add up to 10^%.1f, got 10^%.1f", assessment.GuessesLog10, total)
+	}
+}
@@ -0,0 +1,141 @@
+package password
+
+import "math"
+
+const (
+	// minCompoundPartLength keeps short words such as "an" or "er" from splitting every string.
+	minCompoundPartLength = 3
+	maxCompoundParts      = 4
+	// maxCompoundMatches bounds the compounds found from one start position, so passwords made
+	// of many overlapping words stay cheap without hiding compounds further along.
+	maxCompoundMatches = 512
+)
+
+// compoundLanguages are the languages whose words are joined into compounds.
+var compoundLanguages = map[string]bool{"de": true}
+
+// compoundLinkers are the linking elements (Fugenelemente) German inserts between the parts
+// of a compound, as in "geburtstag-s-kuchen" or "sonne-n-schein"; the empty linker joins
+// parts directly, as in "sommerhaus".
+var compoundLinkers = []string{"", "s", "es", "n", "en", "e", "er"}
+
+// compoundPart is a dictionary word found at a position of the password.
+type compoundPart struct {
+	end  int
+	word string
+	rank int
+}
+
+// compoundMatches finds compounds of two or more words from the dictionaries of a compounding
+// language. The match is ranked by the product of the part ranks, multiplied at every joint
+// with a linking element by the number of elements an attacker has to try there.
+func (e *estimator) compoundMatches(runes []rune) []Match {
+	lowered := lowerRunes(runes)
+	n := len(lowered)
+	var matches []Match
+	for language := range compoundLanguages {
+		// parts[i] lists the most common word for each end position starting at i.
+		parts := make([][]compoundPart, n)
+		found := false
+		for _, dictionary := range e.dictionaries {
+			if dictionary.language != language {
+				continue
+			}
+			for i := 0; i < n; i++ {
+				for j := i + minCompoundPartLength - 1; j < n && j-i < dictionary.maxLength; j++ {
+					word := string(lowered[i : j+1])
+					rank, ok := dictionary.ranks[word]
+					if !ok {
+						continue
+					}
+					parts[i] = addCompoundPart(parts[i], compoundPart{end: j, word: word, rank: rank})
+					found = true
+				}
+			}
+		}
+		if !found {
+			continue
+		}
+
+		var counted int
+		var extend func(start, next int, words []string, rank int)
+		extend = func(start, next int, words []string, rank int) {
+			if len(words) == maxCompoundParts || counted >= maxCompoundMatches {
+				return
+			}
+			for _, linker := range compoundLinkers {
+				at := next + len(linker)
+				if at >= n || !hasRunePrefix(lowered[next:], linker) {
+					continue
+				}
+				joint := 1
+				if linker != "" {
+					joint = len(compoundLinkers) - 1
+				}
+				for _, part := range parts[at] {
+					joined := append(append([]string(nil), words...), part.word)
+					compoundRank := multiplyRanks(rank, joint, part.rank)
+					if counted >= maxCompoundMatches {
+						return
+					}
+					counted++
+					matches = append(matches, Match{
+						Pattern:        PatternDictionary,
+						I:              start,
+						J:              part.end,
+						Token:          string(runes[start : part.end+1]),
+						MatchedWord:    string(lowered[start : part.end+1]),
+						Rank:           compoundRank,
+						DictionaryName: language + "/compounds",
+						Parts:          joined,
+					})
+					extend(start, part.end+1, joined, compoundRank)
+				}
+			}
+		}
+		for i := 0; i < n; i++ {
+			counted = 0
+			for _, part := range parts[i] {
+				extend(i, part.end+1, []string{part.word}, part.rank)
+			}
+		}
+	}
+	return matches
+}
+
+// multiplyRanks multiplies ranks, saturating at the largest int instead of overflowing
+// when a large custom dictionary supplies several rare parts.
+func multiplyRanks(ranks ...int) int {
+	product := 1.0
+	for _, rank := range ranks {
+		product *= float64(rank)
+	}
+	if product >= math.MaxInt {
+		return math.MaxInt
+	}
+	return int(product)
+}
+
+// addCompoundPart keeps one word per end position, the most common one.
+func addCompoundPart(parts []compoundPart, part compoundPart) []compoundPart {
+	for idx := range parts {
+		if parts[idx].end == part.end {
+			if part.rank < parts[idx].rank {
+				parts[idx] = part
+			}
+			return parts
+		}
+	}
+	return append(parts, part)
+}
+
+func hasRunePrefix(runes []rune, prefix string) bool {
+	idx := 0
+	for _, r := range prefix {
+		if idx >= len(runes) || runes[idx] != r {
+			return false
+		}
+		idx++
+	}
+	return true
+}
@@ -0,0 +1,412 @@
+package password
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	dateMinYear = 1000
+	dateMaxYear = 2050
+	// twoDigitYearMin is the earliest year a bare two-digit suffix such as "Anna87" is read as.
+	twoDigitYearMin = 1950
+	// maxDateGuesses caps the credit a date segment earns, however remote its year.
+	maxDateGuesses = 1 << 16
+	// dateSeparators are the characters accepted between day, month and year.
+	dateSeparators = ".-/"
+)
+
+// dateSplits lists, per digit-string length, the cut points separating the three date parts.
+var dateSplits = map[int][][2]int{
+	4: {{1, 2}, {2, 3}},
+	5: {{1, 3}, {2, 3}},
+	6: {{1, 2}, {2, 4}, {4, 5}},
+	7: {{1, 3}, {2, 3}, {4, 5}, {4, 6}},
+	8: {{2, 4}, {4, 6}},
+}
+
+type dmy struct {
+	day, month, year int
+}
+
+// dateMatches finds years, digit-only dates such as "311299" or "19991231", dates with
+// separators such as "31.12.1999", "1999-12-31" or "12/31/99", German day-month dates
+// such as "24.12." and two-digit years appended to a dictionary word such as "Anna87".
+func (e *estimator) dateMatches(runes []rune) []Match {
+	var matches []Match
+	matches = append(matches, yearMatches(runes)...)
+	matches = append(matches, e.twoDigitYearMatches(runes)...)
+	matches = append(matches, compactDateMatches(runes)...)
+	matches = append(matches, separatedDateMatches(runes)...)
+	matches = append(matches, dayMonthMatches(runes)...)
+	return matches
+}
+
+// twoDigitYearMatches only reads two digits as a year right after a dictionary word, since
+// any letter followed by two digits would otherwise turn random passwords into dates.
+func (e *estimator) twoDigitYearMatches(runes []rune) []Match {
+	var matches []Match
+	var lowered []rune
+	for i := 1; i+2 <= len(runes); i++ {
+		token := runes[i : i+2]
+		if !allDigits(token) || !unicode.IsLetter(runes[i-1]) || (i+2 < len(runes) && isDigit(runes[i+2])) {
+			continue
+		}
+		if lowered == nil {
+			lowered = lowerRunes(runes)
+		}
+		if !e.endsWithWord(lowered[:i]) {
+			continue
+		}
+		value, _ := strconv.Atoi(string(token))
+		year := twoToFourDigitYear(value)
+		if year < twoDigitYearMin || year > referenceYear {
+			continue
+		}
+		matches = append(matches, Match{Pattern: PatternDate, I: i, J: i + 1, Token: string(token), Year: year})
+	}
+	return matches
+}
+
+// endsWithWord reports whether the runes end with a dictionary word of at least
+// minCompoundPartLength letters.
+func (e *estimator) endsWithWord(lowered []rune) bool {
+	for _, dictionary := range e.dictionaries {
+		for length := minCompoundPartLength; length <= len(lowered) && length <= dictionary.maxLength; length++ {
+			if _, ok := dictionary.ranks[string(lowered[len(lowered)-length:])]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// separatedDateMatches finds dates whose three parts are divided by the same separator.
+// Slashes follow the US month-first convention when day and month are ambiguous; dots and
+// hyphens follow the German and ISO day-first or year-first conventions.
+func separatedDateMatches(runes []rune) []Match {
+	var matches []Match
+	n := len(runes)
+	for i := 0; i <= n-6; i++ {
+		for j := i + 5; j <= i+9 && j < n; j++ {
+			token := runes[i : j+1]
+			parts, separator, ok := splitSeparatedDate(token)
+			if !ok {
+				continue
+			}
+			values := [3]int{}
+			for idx, part := range parts {
+				values[idx], _ = strconv.Atoi(string(part))
+			}
+			date, ok := mapIntsToDMY(values[0], values[1], values[2])
+			if !ok {
+				continue
+			}
+			if separator == '/' && len(parts[0]) <= 2 && date.day <= 12 && date.month <= 12 {
+				date.day, date.month = values[1], values[0]
+			}
+			matches = append(matches, Match{
+				Pattern:   PatternDate,
+				I:         i,
+				J:         j,
+				Token:     string(token),
+				Day:       date.day,
+				Month:     date.month,
+				Year:      date.year,
+				Separator: string(separator),
+			})
+		}
+	}
+	return removeContainedDates(matches)
+}
+
+// splitSeparatedDate splits a token such as "31.12.1999" into its digit parts. The outer
+// parts have one to four digits, the middle part one or two.
+func splitSeparatedDate(token []rune) ([3][]rune, rune, bool) {
+	var parts [3][]rune
+	var separator rune
+	part, start := 0, 0
+	for idx, r := range token {
+		if isDigit(r) {
+			continue
+		}
+		if part == 2 || !strings.ContainsRune(dateSeparators, r) || (separator != 0 && r != separator) {
+			return parts, 0, false
+		}
+		separator = r
+		parts[part] = token[start:idx]
+		part++
+		start = idx + 1
+	}
+	if part != 2 {
+		return parts, 0, false
+	}
+	parts[2] = token[start:]
+	if len(parts[0]) == 0 || len(parts[0]) > 4 || len(parts[1]) == 0 || len(parts[1]) > 2 || len(parts[2]) == 0 || len(parts[2]) > 4 {
+		return parts, 0, false
+	}
+	return parts, separator, true
+}
+
+// dayMonthMatches finds German day-month dates without a year such as "24.12.", which
+// are only recognised with the trailing dot that German usage requires.
+func dayMonthMatches(runes []rune) []Match {
+	var matches []Match
+	n := len(runes)
+	for i := 0; i <= n-4; i++ {
+		if i > 0 && (isDigit(runes[i-1]) || runes[i-1] == '.') {
+			continue
+		}
+		for j := i + 3; j <= i+5 && j < n; j++ {
+			token := runes[i : j+1]
+			if token[len(token)-1] != '.' || (j+1 < n && isDigit(runes[j+1])) {
+				continue
+			}
+			dayPart, monthPart, ok := strings.Cut(string(token[:len(token)-1]), ".")
+			if !ok || len(dayPart) == 0 || len(dayPart) > 2 || len(monthPart) == 0 || len(monthPart) > 2 ||
+				!allDigits([]rune(dayPart)) || !allDigits([]rune(monthPart)) {
+				continue
+			}
+			day, _ := strconv.Atoi(dayPart)
+			month, _ := strconv.Atoi(monthPart)
+			if day < 1 || day > 31 || month < 1 || month > 12 {
+				continue
+			}
+			matches = append(matches, Match{
+				Pattern:   PatternDate,
+				I:         i,
+				J:         j,
+				Token:     string(token),
+				Day:       day,
+				Month:     month,
+				Separator: ".",
+			})
+		}
+	}
+	return matches
+}
+
+// dateFindings reports the longest non-overlapping dates among the matches.
+func dateFindings(matches []Match) []Finding {
+	var findings []Finding
+	for _, date := range reportedDates(matches) {
+		var message string
+		switch {
+		case date.Month == 0:
+			message = fmt.Sprintf("year %q at positions %d-%d is easy to guess", date.Token, date.I+1, date.J+1)
+		case date.Year == 0:
+			message = fmt.Sprintf("date %q at positions %d-%d (day %d, month %d) is easy to guess", date.Token, date.I+1, date.J+1, date.Day, date.Month)
+		default:
+			message = fmt.Sprintf("date %q at positions %d-%d (read as %04d-%02d-%02d) is easy to guess", date.Token, date.I+1, date.J+1, date.Year, date.Month, date.Day)
+		}
+		findings = append(findings, Finding{
+			Code:        "pattern.date",
+			Message:     message,
+			Severity:    SeverityWarn,
+			Requirement: "date_patterns",
+		}.at(date.I, date.J))
+	}
+	return findings
+}
+
+// reportedDates returns the dates dateFindings reports.
+func reportedDates(matches []Match) []Match {
+	return longestNonOverlapping(matchesOf(matches, PatternDate))
+}
+
+func yearMatches(runes []rune) []Match {
+	var matches []Match
+	for i := 0; i+4 <= len(runes); i++ {
+		token := runes[i : i+4]
+		if !allDigits(token) || (i > 0 && isDigit(runes[i-1])) || (i+4 < len(runes) && isDigit(runes[i+4])) {
+			continue
+		}
+		if (token[0] != '1' || token[1] != '9') && (token[0] != '2' || token[1] != '0') {
+			continue
+		}
+		year, _ := strconv.Atoi(string(token))
+		matches = append(matches, Match{Pattern: PatternDate, I: i, J: i + 3, Token: string(token), Year: year})
+	}
+	return matches
+}
+
+func compactDateMatches(runes []rune) []Match {
+	var candidates []Match
+	n := len(runes)
+	for i := 0; i <= n-4; i++ {
+		for j := i + 3; j <= i+7 && j < n; j++ {
+			token := runes[i : j+1]
+			if !allDigits(token) {
+				continue
+			}
+			best, ok := bestDateSplit(token)
+			if !ok {
+				continue
+			}
+			candidates = append(candidates, Match{
+				Pattern: PatternDate,
+				I:       i,
+				J:       j,
+				Token:   string(token),
+				Day:     best.day,
+				Month:   best.month,
+				Year:    best.year,
+			})
+		}
+	}
+	return removeContainedDates(candidates)
+}
+
+func bestDateSplit(token []rune) (dmy, bool) {
+	var (
+		best  dmy
+		found bool
+	)
+	for _, split := range dateSplits[len(token)] {
+		first, _ := strconv.Atoi(string(token[:split[0]]))
+		second, _ := strconv.Atoi(string(token[split[0]:split[1]]))
+		third, _ := strconv.Atoi(string(token[split[1]:]))
+		candidate, ok := mapIntsToDMY(first, second, third)
+		if !ok {
+			continue
+		}
+		if !found || absInt(candidate.year-referenceYear) < absInt(best.year-referenceYear) {
+			best, found = candidate, true
+		}
+	}
+	return best, found
+}
+
+// mapIntsToDMY interprets three integers as a date with the year first or last.
+func mapIntsToDMY(a, b, c int) (dmy, bool) {
+	if b > 31 || b <= 0 {
+		return dmy{}, false
+	}
+	over12, over31, under1 := 0, 0, 0
+	for _, value := range []int{a, b, c} {
+		if (value > 99 && value < dateMinYear) || value > dateMaxYear {
+			return dmy{}, false
+		}
+		if value > 31 {
+			over31++
+		}
+		if value > 12 {
+			over12++
+		}
+		if value <= 0 {
+			under1++
+		}
+	}
+	if over31 >= 2 || over12 == 3 || under1 >= 2 {
+		return dmy{}, false
+	}
+
+	splits := [][3]int{{c, a, b}, {a, b, c}}
+	for idx, split := range splits {
+		year := split[0]
+		if year < dateMinYear || year > dateMaxYear {
+			continue
+		}
+		day, month, ok := mapSplitToDM(split, idx == 1)
+		if !ok {
+			return dmy{}, false
+		}
+		return dmy{day: day, month: month, year: year}, true
+	}
+	for idx, split := range splits {
+		day, month, ok := mapSplitToDM(split, idx == 1)
+		if ok {
+			return dmy{day: day, month: month, year: twoToFourDigitYear(split[0])}, true
+		}
+	}
+	return dmy{}, false
+}
+
+// mapSplitToDM reads the day and month of a year, x, y split. A date that starts with the
+// year is read as ISO 8601, month before day; one that ends with it as German, day before month.
+func mapSplitToDM(split [3]int, yearFirst bool) (int, int, bool) {
+	if yearFirst {
+		return mapIntsToDM(split[2], split[1])
+	}
+	return mapIntsToDM(split[1], split[2])
+}
+
+func mapIntsToDM(a, b int) (int, int, bool) {
+	for _, pair := range [][2]int{{a, b}, {b, a}} {
+		day, month := pair[0], pair[1]
+		if day >= 1 && day <= 31 && month >= 1 && month <= 12 {
+			return day, month, true
+		}
+	}
+	return 0, 0, false
+}
+
+func twoToFourDigitYear(year int) int {
+	switch {
+	case year > 99:
+		return year
+	case year > 50:
+		return year + 1900
+	default:
+		return year + 2000
+	}
+}
+
+// removeContainedDates drops date matches that lie strictly inside another date match.
+func removeContainedDates(matches []Match) []Match {
+	filtered := matches[:0:0]
+	for idx, match := range matches {
+		contained := false
+		for other, candidate := range matches {
+			if idx == other {
+				continue
+			}
+			if candidate.I <= match.I && candidate.J >= match.J && (candidate.I != match.I || candidate.J != match.J) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			filtered = append(filtered, match)
+		}
+	}
+	return filtered
+}
+
+func dateGuesses(match *Match) float64 {
+	if match.Year == 0 {
+		return 366
+	}
+	space := yearSpace(match.Year)
+	if match.Month == 0 {
+		return space
+	}
+	guesses := space * 365
+	if match.Separator != "" {
+		guesses *= 4
+	}
+	return math.Min(guesses, maxDateGuesses)
+}
+
+func allDigits(runes []rune) bool {
+	for _, r := range runes {
+		if !isDigit(r) {
+			return false
+		}
+	}
+	return len(runes) > 0
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func absInt(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
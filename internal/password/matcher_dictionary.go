@@ -0,0 +1,298 @@
+package password
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// maxL33tSubstitutions bounds the number of substitution tables tried per password.
+const maxL33tSubstitutions = 128
+
+// rankedDictionary maps lowercase words to their frequency rank (1 = most common).
+// Words with umlauts or ß are also ranked under their ae, oe, ue and ss spelling.
+type rankedDictionary struct {
+	name string
+	// language is the code before the slash in the name, e.g. "de" for "de/words".
+	language  string
+	ranks     map[string]int
+	maxLength int
+	// words lists the words in rank order for the mangling rules.
+	words []rankedWord
+}
+
+type rankedWord struct {
+	word   string
+	rank   int
+	length int
+}
+
+func newRankedDictionary(name string, words []string) rankedDictionary {
+	dictionary := rankedDictionary{name: name, ranks: make(map[string]int, len(words))}
+	if language, _, ok := strings.Cut(name, "/"); ok {
+		dictionary.language = language
+	}
+	for idx, word := range words {
+		lowered := string(lowerRunes([]rune(word)))
+		dictionary.add(lowered, idx+1)
+		dictionary.add(umlautSpellings.Replace(lowered), idx+1)
+	}
+	return dictionary
+}
+
+// add ranks the word unless a more common word already has the same spelling.
+func (d *rankedDictionary) add(word string, rank int) {
+	if _, exists := d.ranks[word]; exists {
+		return
+	}
+	d.ranks[word] = rank
+	length := len([]rune(word))
+	d.words = append(d.words, rankedWord{word: word, rank: rank, length: length})
+	if length > d.maxLength {
+		d.maxLength = length
+	}
+}
+
+// l33tTable lists the characters commonly substituted for each letter.
+var l33tTable = map[rune][]rune{
+	'a': {'4', '@'},
+	'b': {'8'},
+	'c': {'(', '{', '[', '<'},
+	'e': {'3'},
+	'g': {'6', '9'},
+	'i': {'1', '!', '|'},
+	'l': {'1', '|', '7'},
+	'o': {'0'},
+	's': {'$', '5'},
+	't': {'+', '7'},
+	'x': {'%'},
+	'z': {'2'},
+}
+
+// l33tReverse maps a substituted character to the letters it may stand for.
+var l33tReverse = func() map[rune][]rune {
+	reverse := make(map[rune][]rune)
+	for _, letter := range []rune("abcegilostxz") {
+		for _, sub := range l33tTable[letter] {
+			reverse[sub] = append(reverse[sub], letter)
+		}
+	}
+	return reverse
+}()
+
+func (e *estimator) dictionaryMatches(runes []rune) []Match {
+	lowered := lowerRunes(runes)
+	var matches []Match
+	for _, dictionary := range e.dictionaries {
+		matches = append(matches, dictionary.match(runes, lowered)...)
+	}
+	return matches
+}
+
+func (d rankedDictionary) match(runes, lowered []rune) []Match {
+	var matches []Match
+	n := len(runes)
+	for i := 0; i < n; i++ {
+		for j := i; j < n && j-i < d.maxLength; j++ {
+			word := string(lowered[i : j+1])
+			rank, ok := d.ranks[word]
+			if !ok {
+				continue
+			}
+			matches = append(matches, Match{
+				Pattern:        PatternDictionary,
+				I:              i,
+				J:              j,
+				Token:          string(runes[i : j+1]),
+				MatchedWord:    word,
+				Rank:           rank,
+				DictionaryName: d.name,
+			})
+		}
+	}
+	return matches
+}
+
+func (e *estimator) reverseDictionaryMatches(runes []rune) []Match {
+	n := len(runes)
+	reversed := make([]rune, n)
+	for idx, r := range runes {
+		reversed[n-1-idx] = r
+	}
+
+	matches := e.dictionaryMatches(reversed)
+	for idx := range matches {
+		match := &matches[idx]
+		match.I, match.J = n-1-match.J, n-1-match.I
+		match.Token = string(runes[match.I : match.J+1])
+		match.Reversed = true
+	}
+	return matches
+}
+
+func (e *estimator) l33tMatches(runes []rune) []Match {
+	type key struct {
+		i, j int
+		word string
+	}
+	seen := map[key]struct{}{}
+
+	var matches []Match
+	for _, sub := range l33tSubstitutions(runes) {
+		translated := make([]rune, len(runes))
+		for idx, r := range runes {
+			if letter, ok := sub[r]; ok {
+				translated[idx] = letter
+				continue
+			}
+			translated[idx] = r
+		}
+
+		for _, match := range e.dictionaryMatches(translated) {
+			token := runes[match.I : match.J+1]
+			if len(token) <= 1 {
+				continue
+			}
+			used := make(map[rune]rune)
+			for _, r := range token {
+				if letter, ok := sub[r]; ok {
+					used[r] = letter
+				}
+			}
+			// Matches without substitutions are already found by the plain dictionary matcher.
+			if len(used) == 0 {
+				continue
+			}
+			k := key{match.I, match.J, match.MatchedWord}
+			if _, ok := seen[k]; ok {
+				continue
+			}
+			seen[k] = struct{}{}
+
+			match.Token = string(token)
+			match.L33t = true
+			match.L33tSub = used
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+// l33tSubstitutions enumerates the ways the l33t characters present in the password can
+// be read back as letters, e.g. "1" as either "i" or "l".
+func l33tSubstitutions(runes []rune) []map[rune]rune {
+	var present []rune
+	seen := map[rune]struct{}{}
+	for _, r := range runes {
+		if _, ok := l33tReverse[r]; !ok {
+			continue
+		}
+		if _, ok := seen[r]; ok {
+			continue
+		}
+		seen[r] = struct{}{}
+		present = append(present, r)
+	}
+	if len(present) == 0 {
+		return nil
+	}
+
+	subs := []map[rune]rune{{}}
+	for _, subbed := range present {
+		var next []map[rune]rune
+		for _, existing := range subs {
+			for _, letter := range l33tReverse[subbed] {
+				if len(next) >= maxL33tSubstitutions {
+					break
+				}
+				extended := make(map[rune]rune, len(existing)+1)
+				for k, v := range existing {
+					extended[k] = v
+				}
+				extended[subbed] = letter
+				next = append(next, extended)
+			}
+		}
+		subs = next
+	}
+	return subs
+}
+
+func dictionaryGuesses(match *Match) float64 {
+	// The rule already accounts for the case and the changes of a mangled word.
+	if match.ManglingRule != "" {
+		return float64(match.Rank)
+	}
+	guesses := float64(match.Rank) * uppercaseVariations(match.Token) * l33tVariations(match)
+	if match.Reversed {
+		guesses *= 2
+	}
+	return guesses
+}
+
+// uppercaseVariations estimates how many capitalisation variants an attacker tries.
+func uppercaseVariations(token string) float64 {
+	runes := []rune(token)
+	upper, lower := 0, 0
+	for _, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+	if upper == 0 {
+		return 1
+	}
+	// Capitalised first letter, last letter or all caps are the most common patterns.
+	if lower == 0 {
+		return 2
+	}
+	if upper == 1 && (unicode.IsUpper(runes[0]) || unicode.IsUpper(runes[len(runes)-1])) {
+		return 2
+	}
+	variations := 0.0
+	for i := 1; i <= int(math.Min(float64(upper), float64(lower))); i++ {
+		variations += binomial(upper+lower, i)
+	}
+	return variations
+}
+
+func l33tVariations(match *Match) float64 {
+	if !match.L33t {
+		return 1
+	}
+	variations := 1.0
+	lowered := lowerRunes([]rune(match.Token))
+	for subbed, unsubbed := range match.L33tSub {
+		subbedCount, unsubbedCount := 0, 0
+		for _, r := range lowered {
+			switch r {
+			case subbed:
+				subbedCount++
+			case unsubbed:
+				unsubbedCount++
+			}
+		}
+		if subbedCount == 0 || unsubbedCount == 0 {
+			// Either every occurrence is substituted or none is: one extra bit of guessing.
+			variations *= 2
+			continue
+		}
+		possibilities := 0.0
+		for i := 1; i <= int(math.Min(float64(subbedCount), float64(unsubbedCount))); i++ {
+			possibilities += binomial(subbedCount+unsubbedCount, i)
+		}
+		variations *= possibilities
+	}
+	return variations
+}
+
+func lowerRunes(runes []rune) []rune {
+	lowered := make([]rune, len(runes))
+	for idx, r := range runes {
+		lowered[idx] = unicode.ToLower(r)
+	}
+	return lowered
+}
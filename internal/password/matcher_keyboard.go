@@ -0,0 +1,338 @@
+package password
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// minReportedWalkLength is the fewest distinct keystrokes a keyboard walk needs to be
+// reported as a finding; shorter walks such as "123" are too common to be worth a hint.
+const minReportedWalkLength = 4
+
+// keyboardGraph records, for every key, its neighbours in a fixed direction order.
+// Each neighbour is the key's token (unshifted followed by shifted character) or
+// empty when there is no key in that direction.
+type keyboardGraph struct {
+	name             string
+	adjacency        map[rune][]string
+	shifted          map[rune]bool
+	startingPosition float64
+	averageDegree    float64
+}
+
+const qwertyLayout = `
+` + "`" + `~ 1! 2@ 3# 4$ 5% 6^ 7& 8* 9( 0) -_ =+
+    qQ wW eE rR tT yY uU iI oO pP [{ ]} \|
+     aA sS dD fF gG hH jJ kK lL ;: '"
+      zZ xX cC vV bB nN mM ,< .> /?
+`
+
+// qwertzLayout is the German keyboard layout.
+const qwertzLayout = `
+^° 1! 2" 3§ 4$ 5% 6& 7/ 8( 9) 0= ß? ´` + "`" + `
+    qQ wW eE rR tT zZ uU iI oO pP üÜ +*
+     aA sS dD fF gG hH jJ kK lL öÖ äÄ #'
+   <> yY xX cC vV bB nN mM ,; .: -_
+`
+
+// azertyLayout is the French keyboard layout, where the digits require shift.
+const azertyLayout = `
+   &1 é2 "3 '4 (5 -6 è7 _8 ç9 à0 )° =+
+    aA zZ eE rR tT yY uU iI oO pP ^¨ $£
+     qQ sS dD fF gG hH jJ kK lL mM ù% *µ
+   <> wW xX cC vV bB nN ,? ;. :/ !§
+`
+
+const numpadLayout = `
+  / * -
+7 8 9 +
+4 5 6
+1 2 3
+  0 .
+`
+
+// KeyboardLayouts lists the layout names accepted in Policy.KeyboardLayouts.
+var KeyboardLayouts = []string{"qwerty", "qwertz", "azerty", "numpad"}
+
+func defaultKeyboardGraphs() []keyboardGraph {
+	graphs, _ := keyboardGraphs(KeyboardLayouts)
+	return graphs
+}
+
+// keyboardGraphs builds the adjacency graphs for the named layouts.
+func keyboardGraphs(names []string) ([]keyboardGraph, error) {
+	graphs := make([]keyboardGraph, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "qwerty":
+			graphs = append(graphs, buildKeyboardGraph(name, qwertyLayout, true))
+		case "qwertz":
+			graphs = append(graphs, buildKeyboardGraph(name, qwertzLayout, true))
+		case "azerty":
+			graphs = append(graphs, buildKeyboardGraph(name, azertyLayout, true))
+		case "numpad":
+			graphs = append(graphs, buildKeyboardGraph(name, numpadLayout, false))
+		default:
+			return nil, fmt.Errorf("unknown keyboard layout: %s", name)
+		}
+	}
+	return graphs, nil
+}
+
+type keyPosition struct{ x, y int }
+
+// buildKeyboardGraph derives the adjacency graph from an ASCII drawing of the layout.
+// Slanted layouts model staggered typewriter rows, where each row is shifted by half a
+// key; aligned layouts model grids such as the numeric keypad.
+func buildKeyboardGraph(name, layout string, slanted bool) keyboardGraph {
+	positions := map[keyPosition]string{}
+	lines := strings.Split(layout, "\n")
+	tokenSize := 0
+	for _, line := range lines {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			tokenSize = len([]rune(fields[0]))
+			break
+		}
+	}
+	xUnit := tokenSize + 1
+
+	for y, line := range lines {
+		slant := 0
+		if slanted {
+			slant = y - 1
+		}
+		runes := []rune(line)
+		column := 0
+		for column < len(runes) {
+			if runes[column] == ' ' {
+				column++
+				continue
+			}
+			start := column
+			for column < len(runes) && runes[column] != ' ' {
+				column++
+			}
+			positions[keyPosition{x: (start - slant) / xUnit, y: y}] = string(runes[start:column])
+		}
+	}
+
+	graph := keyboardGraph{name: name, adjacency: map[rune][]string{}, shifted: map[rune]bool{}}
+	degrees := 0
+	for position, token := range positions {
+		neighbours := make([]string, 0, 8)
+		for _, coord := range adjacentPositions(position, slanted) {
+			neighbours = append(neighbours, positions[coord])
+		}
+		for idx, r := range []rune(token) {
+			graph.adjacency[r] = neighbours
+			if slanted && idx > 0 {
+				graph.shifted[r] = true
+			}
+			for _, neighbour := range neighbours {
+				if neighbour != "" {
+					degrees++
+				}
+			}
+		}
+	}
+	graph.startingPosition = float64(len(graph.adjacency))
+	if len(graph.adjacency) > 0 {
+		graph.averageDegree = float64(degrees) / float64(len(graph.adjacency))
+	}
+	return graph
+}
+
+func adjacentPositions(p keyPosition, slanted bool) []keyPosition {
+	if slanted {
+		return []keyPosition{
+			{p.x - 1, p.y}, {p.x, p.y - 1}, {p.x + 1, p.y - 1},
+			{p.x + 1, p.y}, {p.x, p.y + 1}, {p.x - 1, p.y + 1},
+		}
+	}
+	return []keyPosition{
+		{p.x - 1, p.y}, {p.x - 1, p.y - 1}, {p.x, p.y - 1}, {p.x + 1, p.y - 1},
+		{p.x + 1, p.y}, {p.x + 1, p.y + 1}, {p.x, p.y + 1}, {p.x - 1, p.y + 1},
+	}
+}
+
+// isShifted reports whether r is the shifted character of its key.
+func (g keyboardGraph) isShifted(r rune) bool {
+	return g.shifted[r]
+}
+
+func (e *estimator) spatialMatches(runes []rune) []Match {
+	var matches []Match
+	for _, graph := range e.graphs {
+		matches = append(matches, graph.walks(runes)...)
+	}
+	return matches
+}
+
+// keyboardWalks returns the longest non-overlapping keyboard walks of the matches,
+// ordered by position. Walks that are plain sequences such as "1234" or dates such as
+// "2024" are left to their own matchers.
+func keyboardWalks(matches []Match) []Match {
+	dates := matchesOf(matches, PatternDate)
+
+	var walks []Match
+	for _, candidate := range matchesOf(matches, PatternSpatial) {
+		if candidate.J-candidate.I+1-candidate.Repeats < minReportedWalkLength || isPlainSequence([]rune(candidate.Token)) || coveredBy(candidate, dates) {
+			continue
+		}
+		walks = append(walks, candidate)
+	}
+	return longestNonOverlapping(walks)
+}
+
+// keyboardFindings reports the keyboard walks among the matches.
+func keyboardFindings(matches []Match) []Finding {
+	var findings []Finding
+	for _, walk := range keyboardWalks(matches) {
+		findings = append(findings, Finding{
+			Code:        "pattern.keyboard",
+			Message:     fmt.Sprintf("keyboard walk %q on the %s layout at positions %d-%d", walk.Token, walk.Graph, walk.I+1, walk.J+1),
+			Severity:    SeverityWarn,
+			Requirement: "keyboard_patterns",
+		}.at(walk.I, walk.J))
+	}
+	return findings
+}
+
+// matchesOf returns the matches of the pattern.
+func matchesOf(matches []Match, pattern Pattern) []Match {
+	var found []Match
+	for _, match := range matches {
+		if match.Pattern == pattern {
+			found = append(found, match)
+		}
+	}
+	return found
+}
+
+func coveredBy(candidate Match, matches []Match) bool {
+	for _, match := range matches {
+		if match.I <= candidate.I && candidate.J <= match.J {
+			return true
+		}
+	}
+	return false
+}
+
+func isPlainSequence(runes []rune) bool {
+	matches := sequenceMatches(runes)
+	return len(matches) == 1 && matches[0].I == 0 && matches[0].J == len(runes)-1
+}
+
+// walks finds runs of at least three keys where each key is adjacent to the previous one
+// or repeats it. A walk needs at least one step to a neighbouring key.
+func (g keyboardGraph) walks(runes []rune) []Match {
+	var matches []Match
+	n := len(runes)
+	i := 0
+	for i < n-1 {
+		j := i + 1
+		lastDirection := -1
+		turns := 0
+		repeats := 0
+		shiftedCount := 0
+		if g.isShifted(runes[i]) {
+			shiftedCount = 1
+		}
+		for {
+			found := false
+			if j < n && runes[j] == runes[j-1] && g.adjacency[runes[j]] != nil {
+				found = true
+				repeats++
+				if g.isShifted(runes[j]) {
+					shiftedCount++
+				}
+			} else if j < n {
+				for direction, neighbour := range g.adjacency[runes[j-1]] {
+					if neighbour == "" {
+						continue
+					}
+					if !strings.ContainsRune(neighbour, runes[j]) {
+						continue
+					}
+					found = true
+					if g.isShifted(runes[j]) {
+						shiftedCount++
+					}
+					if direction != lastDirection {
+						turns++
+						lastDirection = direction
+					}
+					break
+				}
+			}
+			if found {
+				j++
+				continue
+			}
+			if j-i > 2 && turns > 0 {
+				matches = append(matches, Match{
+					Pattern:      PatternSpatial,
+					I:            i,
+					J:            j - 1,
+					Token:        string(runes[i:j]),
+					Graph:        g.name,
+					Turns:        turns,
+					Repeats:      repeats,
+					ShiftedCount: shiftedCount,
+				})
+			}
+			i = j
+			break
+		}
+	}
+	return matches
+}
+
+func (e *estimator) spatialGuesses(match *Match) float64 {
+	var graph keyboardGraph
+	for _, candidate := range e.graphs {
+		if candidate.name == match.Graph {
+			graph = candidate
+			break
+		}
+	}
+
+	length := len([]rune(match.Token))
+	steps := length - match.Repeats
+	guesses := 0.0
+	for i := 2; i <= steps; i++ {
+		possibleTurns := match.Turns
+		if i-1 < possibleTurns {
+			possibleTurns = i - 1
+		}
+		for j := 1; j <= possibleTurns; j++ {
+			guesses += binomial(i-1, j-1) * graph.startingPosition * math.Pow(graph.averageDegree, float64(j))
+		}
+	}
+
+	if match.ShiftedCount > 0 {
+		shifted := match.ShiftedCount
+		unshifted := length - shifted
+		if unshifted == 0 {
+			guesses *= 2
+		} else {
+			variations := 0.0
+			limit := shifted
+			if unshifted < limit {
+				limit = unshifted
+			}
+			for i := 1; i <= limit; i++ {
+				variations += binomial(shifted+unshifted, i)
+			}
+			guesses *= variations
+		}
+	}
+
+	// Each repeated key could have occurred after any of the other keys of the walk.
+	if match.Repeats > 0 {
+		guesses *= binomial(length-1, match.Repeats)
+	}
+	return guesses
+}
@@ -0,0 +1,405 @@
+package password
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	// manglingYears is how many years back from referenceYear the year rules cover.
+	manglingYears = 75
+	// maxManglingCandidates bounds the rule applications tried per password.
+	maxManglingCandidates = 200000
+	// maxManglingWordLength is the longest dictionary word the rules are applied to.
+	maxManglingWordLength = 64
+)
+
+//go:embed mangling/best64.rule
+var best64Rules string
+
+// manglingRule is a hashcat rule: a sequence of functions applied to a dictionary word.
+type manglingRule struct {
+	text      string
+	functions []manglingFunction
+	// lengths maps the length of a word to the length of the rule's output, or -1 when the
+	// rule rejects words of that length; no supported function depends on more than the length.
+	lengths [maxManglingWordLength + 1]int
+}
+
+type manglingFunction struct {
+	name rune
+	args []rune
+}
+
+// manglingArgs is the number of arguments of each supported rule function.
+var manglingArgs = map[rune]int{
+	':': 0, 'l': 0, 'u': 0, 'c': 0, 'C': 0, 't': 0, 'T': 1, 'r': 0, 'd': 0, 'f': 0,
+	'{': 0, '}': 0, '$': 1, '^': 1, '[': 0, ']': 0, 'D': 1, '\'': 1, 's': 2, 'x': 2,
+	'i': 2, 'o': 2, 'z': 1, 'Z': 1,
+}
+
+// manglingRules are the best64 subset followed by appended years, most recent first, plain
+// and capitalised, which hashcat users add for passwords such as "Sommer2024".
+var manglingRules = func() []manglingRule {
+	var texts []string
+	for _, line := range strings.Split(best64Rules, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			texts = append(texts, line)
+		}
+	}
+	for year := referenceYear; year > referenceYear-manglingYears; year-- {
+		appendYear := ""
+		for _, digit := range fmt.Sprint(year) {
+			appendYear += " $" + string(digit)
+		}
+		texts = append(texts, appendYear[1:], "c"+appendYear)
+	}
+	rules := make([]manglingRule, 0, len(texts))
+	for _, text := range texts {
+		rule, err := parseManglingRule(text)
+		if err != nil {
+			panic(fmt.Sprintf("mangling rule %q: %v", text, err))
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}()
+
+func parseManglingRule(text string) (manglingRule, error) {
+	rule := manglingRule{text: text}
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return manglingRule{}, fmt.Errorf("empty rule")
+	}
+	for idx := 0; idx < len(runes); idx++ {
+		name := runes[idx]
+		if name == ' ' {
+			continue
+		}
+		count, ok := manglingArgs[name]
+		if !ok {
+			return manglingRule{}, fmt.Errorf("unsupported function %q", name)
+		}
+		if idx+count >= len(runes) {
+			return manglingRule{}, fmt.Errorf("function %q needs %d argument(s)", name, count)
+		}
+		rule.functions = append(rule.functions, manglingFunction{name: name, args: runes[idx+1 : idx+1+count]})
+		idx += count
+	}
+	for length := range rule.lengths {
+		rule.lengths[length] = -1
+		if output, ok := rule.apply([]rune(strings.Repeat("a", length))); ok {
+			rule.lengths[length] = len(output)
+		}
+	}
+	return rule, nil
+}
+
+// apply runs the rule on the word; ok is false when a function rejects the word.
+func (r *manglingRule) apply(word []rune) ([]rune, bool) {
+	out := append([]rune(nil), word...)
+	for _, function := range r.functions {
+		var ok bool
+		if out, ok = function.apply(out); !ok {
+			return nil, false
+		}
+	}
+	return out, true
+}
+
+func (f manglingFunction) apply(w []rune) ([]rune, bool) {
+	switch f.name {
+	case ':':
+		return w, true
+	case 'l':
+		return mapRunes(w, unicode.ToLower), true
+	case 'u':
+		return mapRunes(w, unicode.ToUpper), true
+	case 'c':
+		w = mapRunes(w, unicode.ToLower)
+		if len(w) > 0 {
+			w[0] = unicode.ToUpper(w[0])
+		}
+		return w, true
+	case 'C':
+		w = mapRunes(w, unicode.ToUpper)
+		if len(w) > 0 {
+			w[0] = unicode.ToLower(w[0])
+		}
+		return w, true
+	case 't':
+		return mapRunes(w, toggleCase), true
+	case 'T':
+		if n := rulePosition(f.args[0]); n < len(w) {
+			w[n] = toggleCase(w[n])
+		}
+		return w, true
+	case 'r':
+		reversed := make([]rune, len(w))
+		for idx, r := range w {
+			reversed[len(w)-1-idx] = r
+		}
+		return reversed, true
+	case 'd':
+		return append(w, w...), true
+	case 'f':
+		reflected := append([]rune(nil), w...)
+		for idx := len(w) - 1; idx >= 0; idx-- {
+			reflected = append(reflected, w[idx])
+		}
+		return reflected, true
+	case '{':
+		if len(w) > 0 {
+			w = append(w[1:], w[0])
+		}
+		return w, true
+	case '}':
+		if len(w) > 0 {
+			w = append([]rune{w[len(w)-1]}, w[:len(w)-1]...)
+		}
+		return w, true
+	case '$':
+		return append(w, f.args[0]), true
+	case '^':
+		return append([]rune{f.args[0]}, w...), true
+	case '[':
+		if len(w) > 0 {
+			w = w[1:]
+		}
+		return w, true
+	case ']':
+		if len(w) > 0 {
+			w = w[:len(w)-1]
+		}
+		return w, true
+	case 'D':
+		if n := rulePosition(f.args[0]); n < len(w) {
+			w = append(w[:n], w[n+1:]...)
+		}
+		return w, true
+	case '\'':
+		if n := rulePosition(f.args[0]); n < len(w) {
+			w = w[:n]
+		}
+		return w, true
+	case 's':
+		return mapRunes(w, func(r rune) rune {
+			if r == f.args[0] {
+				return f.args[1]
+			}
+			return r
+		}), true
+	case 'x':
+		start, length := rulePosition(f.args[0]), rulePosition(f.args[1])
+		if start >= len(w) || start+length > len(w) {
+			return nil, false
+		}
+		return w[start : start+length], true
+	case 'i':
+		if n := rulePosition(f.args[0]); n <= len(w) {
+			w = append(w[:n], append([]rune{f.args[1]}, w[n:]...)...)
+		}
+		return w, true
+	case 'o':
+		if n := rulePosition(f.args[0]); n < len(w) {
+			w[n] = f.args[1]
+		}
+		return w, true
+	case 'z':
+		if len(w) > 0 {
+			w = append([]rune(strings.Repeat(string(w[0]), rulePosition(f.args[0]))), w...)
+		}
+		return w, true
+	case 'Z':
+		if len(w) > 0 {
+			w = append(w, []rune(strings.Repeat(string(w[len(w)-1]), rulePosition(f.args[0])))...)
+		}
+		return w, true
+	}
+	return nil, false
+}
+
+// rulePosition decodes hashcat's position arguments 0-9 and A-Z.
+func rulePosition(r rune) int {
+	if r >= 'A' && r <= 'Z' {
+		return int(r-'A') + 10
+	}
+	return int(r - '0')
+}
+
+func toggleCase(r rune) rune {
+	if unicode.IsUpper(r) {
+		return unicode.ToLower(r)
+	}
+	return unicode.ToUpper(r)
+}
+
+func mapRunes(w []rune, mapping func(rune) rune) []rune {
+	mapped := make([]rune, len(w))
+	for idx, r := range w {
+		mapped[idx] = mapping(r)
+	}
+	return mapped
+}
+
+// mangledMatch simulates a rule-based attack in which every rule is applied to a word before
+// the next word of the list is tried, so the password is found after (word rank - 1) * rules +
+// rule position guesses. It returns the lowest-ranked match covering the whole password. Only
+// the rules that turn a word's length into the password's length are applied, and at most
+// maxManglingCandidates times. Passwords that are a dictionary word apart from their case are
+// left to the dictionary matcher.
+func (e *estimator) mangledMatch(runes []rune) (Match, bool) {
+	password := string(runes)
+	lowered := string(lowerRunes(runes))
+	for _, dictionary := range e.dictionaries {
+		if _, ok := dictionary.ranks[lowered]; ok {
+			return Match{}, false
+		}
+	}
+	// rulesByLength lists the rules producing the password's length from words of each length.
+	var rulesByLength [maxManglingWordLength + 1][]int
+	for ruleIdx := range manglingRules {
+		for length, output := range manglingRules[ruleIdx].lengths {
+			if output == len(runes) {
+				rulesByLength[length] = append(rulesByLength[length], ruleIdx)
+			}
+		}
+	}
+
+	var best Match
+	found := false
+	candidates := 0
+	for _, dictionary := range e.dictionaries {
+	words:
+		for _, entry := range dictionary.words {
+			if entry.length > maxManglingWordLength {
+				continue
+			}
+			for _, ruleIdx := range rulesByLength[entry.length] {
+				rank := (entry.rank-1)*len(manglingRules) + ruleIdx + 1
+				if found && rank >= best.Rank {
+					break words
+				}
+				if candidates++; candidates > maxManglingCandidates {
+					return best, found
+				}
+				rule := &manglingRules[ruleIdx]
+				if output, ok := rule.apply([]rune(entry.word)); !ok || string(output) != password {
+					continue
+				}
+				best = Match{
+					Pattern:        PatternDictionary,
+					I:              0,
+					J:              len(runes) - 1,
+					Token:          password,
+					MatchedWord:    entry.word,
+					Rank:           rank,
+					DictionaryName: dictionary.name,
+					ManglingRule:   rule.text,
+				}
+				found = true
+				break words
+			}
+		}
+	}
+	return best, found
+}
+
+// describeManglingRule spells out the functions of a rule, e.g. "capitalise, append \"2024\"".
+func describeManglingRule(text string) string {
+	rule, err := parseManglingRule(text)
+	if err != nil {
+		return text
+	}
+	var steps []string
+	var appended, prepended []rune
+	flush := func() {
+		if len(appended) > 0 {
+			steps = append(steps, fmt.Sprintf("append %q", string(appended)))
+		}
+		if len(prepended) > 0 {
+			steps = append(steps, fmt.Sprintf("prepend %q", string(prepended)))
+		}
+		appended, prepended = nil, nil
+	}
+	for _, function := range rule.functions {
+		switch function.name {
+		case '$':
+			appended = append(appended, function.args[0])
+			continue
+		case '^':
+			prepended = append([]rune{function.args[0]}, prepended...)
+			continue
+		}
+		flush()
+		var step string
+		switch function.name {
+		case ':':
+			step = "keep the word"
+		case 'l':
+			step = "lowercase"
+		case 'u':
+			step = "uppercase"
+		case 'c':
+			step = "capitalise"
+		case 'C':
+			step = "uppercase all but the first letter"
+		case 't':
+			step = "toggle the case"
+		case 'T':
+			step = fmt.Sprintf("toggle the case of character %d", rulePosition(function.args[0])+1)
+		case 'r':
+			step = "reverse"
+		case 'd':
+			step = "duplicate"
+		case 'f':
+			step = "append the word reversed"
+		case '{':
+			step = "rotate left"
+		case '}':
+			step = "rotate right"
+		case '[':
+			step = "delete the first character"
+		case ']':
+			step = "delete the last character"
+		case 'D':
+			step = fmt.Sprintf("delete character %d", rulePosition(function.args[0])+1)
+		case '\'':
+			step = fmt.Sprintf("keep the first %d characters", rulePosition(function.args[0]))
+		case 's':
+			step = fmt.Sprintf("replace %q with %q", function.args[0], function.args[1])
+		case 'x':
+			step = fmt.Sprintf("keep %d characters from character %d", rulePosition(function.args[1]), rulePosition(function.args[0])+1)
+		case 'i':
+			step = fmt.Sprintf("insert %q at character %d", function.args[1], rulePosition(function.args[0])+1)
+		case 'o':
+			step = fmt.Sprintf("overwrite character %d with %q", rulePosition(function.args[0])+1, function.args[1])
+		case 'z':
+			step = fmt.Sprintf("repeat the first character %d times", rulePosition(function.args[0]))
+		case 'Z':
+			step = fmt.Sprintf("repeat the last character %d times", rulePosition(function.args[0]))
+		}
+		steps = append(steps, step)
+	}
+	flush()
+	return strings.Join(steps, ", ")
+}
+
+// manglingFindings reports a password an attacker finds by mangling a dictionary word, taking
+// the match mangledMatch added to the matches.
+func manglingFindings(matches []Match) []Finding {
+	for _, match := range matches {
+		if match.ManglingRule == "" {
+			continue
+		}
+		return []Finding{{
+			Code:        "password.mangled",
+			Message:     fmt.Sprintf("password is the word %q from %s changed by the rule %q (%s); a rule-based attack tries it after about %d guesses", match.MatchedWord, match.DictionaryName, match.ManglingRule, describeManglingRule(match.ManglingRule), match.Rank),
+			Severity:    SeverityWarn,
+			Requirement: "dictionary_rules",
+		}}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package password
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestManglingRulesApplyHashcatFunctions(t *testing.T) {
+	for rule, want := range map[string]string{
+		":":          "sommer",
+		"c":          "Sommer",
+		"u":          "SOMMER",
+		"C":          "sOMMER",
+		"t T0":       "sOMMER",
+		"r":          "remmos",
+		"d":          "sommersommer",
+		"f":          "sommerremmos",
+		"{":          "ommers",
+		"}":          "rsomme",
+		"$1 $2 $3":   "sommer123",
+		"^e ^h ^t":   "thesommer",
+		"[ ] D1":     "ome",
+		"'4":         "somm",
+		"so0 se3":    "s0mm3r",
+		"x13":        "omm",
+		"i2! o0S":    "So!mmer",
+		"z2 Z1":      "sssommerr",
+		"c $2 $0 $2": "Sommer202",
+	} {
+		parsed, err := parseManglingRule(rule)
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", rule, err)
+		}
+		got, ok := parsed.apply([]rune("sommer"))
+		if !ok || string(got) != want {
+			t.Fatalf("%q: expected %q, got %q", rule, want, string(got))
+		}
+		if parsed.lengths[len("sommer")] != len(want) {
+			t.Fatalf("%q: expected output length %d, got %d", rule, len(want), parsed.lengths[len("sommer")])
+		}
+	}
+	for _, rule := range []string{"", "$", "s1", "X"} {
+		if _, err := parseManglingRule(rule); err == nil {
+			t.Fatalf("expected rule %q to be rejected", rule)
+		}
+	}
+}
+
+func TestManglingRulesShipBest64(t *testing.T) {
+	best64 := 0
+	for _, line := range strings.Split(best64Rules, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			best64++
+		}
+	}
+	if best64 != 64 {
+		t.Fatalf("expected 64 best64 rules, got %d", best64)
+	}
+	if want := 64 + 2*manglingYears; len(manglingRules) != want {
+		t.Fatalf("expected %d rules including the years, got %d", want, len(manglingRules))
+	}
+}
+
+func TestMangledMatchNamesWordAndRule(t *testing.T) {
+	est := newEstimator()
+	year := referenceYear - 2
+	for password, want := range map[string]Match{
+		fmt.Sprintf("Sommer%d", year): {MatchedWord: "sommer", ManglingRule: "c" + appendedDigits(year)},
+		"dragondragon":                {MatchedWord: "dragon", ManglingRule: "d"},
+		"Monkey!":                     {MatchedWord: "monkey", ManglingRule: "c $!"},
+	} {
+		match, ok := est.mangledMatch([]rune(password))
+		if !ok {
+			t.Fatalf("%q: expected a mangled dictionary word", password)
+		}
+		if match.MatchedWord != want.MatchedWord || match.ManglingRule != want.ManglingRule {
+			t.Fatalf("%q: expected %q with rule %q, got %+v", password, want.MatchedWord, want.ManglingRule, match)
+		}
+		if match.I != 0 || match.J != len(password)-1 || match.Rank < 1 {
+			t.Fatalf("%q: expected a ranked match of the whole password, got %+v", password, match)
+		}
+	}
+	for _, password := range []string{"sommer", "SOMMER", "gravel7tundra", "Xq7#vP2m!"} {
+		if match, ok := est.mangledMatch([]rune(password)); ok {
+			t.Fatalf("%q: expected no mangled match, got %+v", password, match)
+		}
+	}
+}
+
+func TestMangledMatchIsBounded(t *testing.T) {
+	words := make([]string, 0, 2*maxManglingCandidates)
+	for i := 0; i < cap(words); i++ {
+		words = append(words, fmt.Sprintf("w%07d", i))
+	}
+	est := &estimator{dictionaries: []rankedDictionary{newRankedDictionary("large", words)}}
+	if _, ok := est.mangledMatch([]rune(fmt.Sprintf("W%07d!", len(words)-1))); ok {
+		t.Fatal("expected the search to stop before the last word of a large list")
+	}
+	if match, ok := est.mangledMatch([]rune("W0000001!")); !ok || match.MatchedWord != "w0000001" {
+		t.Fatalf("expected an early word to be found, got %+v", match)
+	}
+}
+
+func TestManglingFindingReportsRuleAndRank(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	password := fmt.Sprintf("Sommer%d", referenceYear-2)
+	assessment := evaluator.Assess(password)
+	var finding *Finding
+	for i := range assessment.Findings {
+		if assessment.Findings[i].Code == "password.mangled" {
+			finding = &assessment.Findings[i]
+		}
+	}
+	if finding == nil {
+		t.Fatalf("expected a mangling finding, got %+v", assessment.Findings)
+	}
+	wantDescription := fmt.Sprintf(`capitalise, append "%d"`, referenceYear-2)
+	if finding.Severity != SeverityWarn || !strings.Contains(finding.Message, `"sommer"`) || !strings.Contains(finding.Message, wantDescription) {
+		t.Fatalf("unexpected finding %+v", finding)
+	}
+	if assessment.Strength == StrengthStrong {
+		t.Fatalf("expected a mangled word not to be strong, got %v", assessment.Strength)
+	}
+
+	disabled, err := NewEvaluator(Policy{MinLength: 8, Rules: map[string]bool{"mangling": false}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, finding := range disabled.Assess(password).Findings {
+		if finding.Code == "password.mangled" {
+			t.Fatalf("expected the disabled rule not to report, got %+v", finding)
+		}
+	}
+}
+
+func appendedDigits(year int) string {
+	var rule string
+	for _, digit := range fmt.Sprint(year) {
+		rule += " $" + string(digit)
+	}
+	return rule
+}
+
+func TestManglingFindingReusesTheEstimate(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// The username is only a dictionary word for the estimate, so the rule can only report
+	// the mangled username if it reads the estimate's matches.
+	assessment := evaluator.AssessWithContext("Zentrovax!", EvaluationContext{Username: "zentrovax"})
+	if len(assessment.Matches) != 1 || assessment.Matches[0].ManglingRule != "c $!" {
+		t.Fatalf("expected the estimate to use the mangled username, got %+v", assessment.Matches)
+	}
+	for _, finding := range assessment.Findings {
+		if finding.Code == "password.mangled" {
+			if !strings.Contains(finding.Message, `"zentrovax" from user_inputs`) {
+				t.Fatalf("unexpected finding %+v", finding)
+			}
+			return
+		}
+	}
+	t.Fatalf("expected a mangling finding, got %+v", assessment.Findings)
+}
@@ -0,0 +1,98 @@
+package password
+
+import "fmt"
+
+const (
+	// minRepeatLength is the shortest run of a single repeated character reported as a repeat.
+	minRepeatLength = 3
+	// minRepeatedSubstringCount is how often a longer base such as "abc" must occur in a row.
+	minRepeatedSubstringCount = 2
+)
+
+// repeatMatches finds runs of the same character such as "aaaa" or "1111" and repeated
+// substrings such as "abcabcabc". At each position the repeat covering the most characters
+// wins; among equally long repeats the shortest base is kept, so "aaaa" repeats "a". Bases
+// are at most maxEstimateLength runes long, which keeps long passwords cheap to scan.
+func (e *estimator) repeatMatches(runes []rune) []Match {
+	var matches []Match
+	n := len(runes)
+	for i := 0; i < n; {
+		bestBase, bestCount := 0, 0
+		for base := 1; i+2*base <= n && base <= maxEstimateLength; base++ {
+			count := 1
+			for i+(count+1)*base <= n && runesEqual(runes[i:i+base], runes[i+count*base:i+(count+1)*base]) {
+				count++
+			}
+			minCount := minRepeatedSubstringCount
+			if base == 1 {
+				minCount = minRepeatLength
+			}
+			if count >= minCount && base*count > bestBase*bestCount {
+				bestBase, bestCount = base, count
+			}
+		}
+		if bestCount == 0 {
+			i++
+			continue
+		}
+
+		j := i + bestBase*bestCount - 1
+		base := string(runes[i : i+bestBase])
+		matches = append(matches, Match{
+			Pattern:     PatternRepeat,
+			I:           i,
+			J:           j,
+			Token:       string(runes[i : j+1]),
+			BaseToken:   base,
+			BaseGuesses: e.estimate(base).Guesses,
+			RepeatCount: bestCount,
+		})
+		i = j + 1
+	}
+	return matches
+}
+
+func runesEqual(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for idx := range a {
+		if a[idx] != b[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+// patternFindings reports the longest non-overlapping sequences and repeats among the matches,
+// leaving out those inside a date reported by dateFindings.
+func patternFindings(matches []Match) []Finding {
+	var findings []Finding
+	dates := reportedDates(matches)
+	for _, match := range longestNonOverlapping(append(matchesOf(matches, PatternSequence), matchesOf(matches, PatternRepeat)...)) {
+		// "987" in "1987" or "999" in "31.12.1999" is part of the date already reported.
+		if coveredBy(match, dates) {
+			continue
+		}
+		if match.Pattern == PatternSequence {
+			direction := "ascending"
+			if !match.Ascending {
+				direction = "descending"
+			}
+			findings = append(findings, Finding{
+				Code:        "pattern.sequence",
+				Message:     fmt.Sprintf("%s sequence %q at positions %d-%d is easy to guess", direction, match.Token, match.I+1, match.J+1),
+				Severity:    SeverityWarn,
+				Requirement: "sequence_patterns",
+			}.at(match.I, match.J))
+			continue
+		}
+		findings = append(findings, Finding{
+			Code:        "pattern.repeat",
+			Message:     fmt.Sprintf("%q repeats %q %d times at positions %d-%d", match.Token, match.BaseToken, match.RepeatCount, match.I+1, match.J+1),
+			Severity:    SeverityWarn,
+			Requirement: "repeat_patterns",
+		}.at(match.I, match.J))
+	}
+	return findings
+}
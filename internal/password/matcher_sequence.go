@@ -0,0 +1,109 @@
+package password
+
+import "unicode"
+
+// minSequenceLength is the shortest run of consecutive characters reported as a sequence.
+const minSequenceLength = 3
+
+// sequenceMatches finds runs such as "abc", "CBA", "6789" or "αβγ" where each character
+// follows the previous one in its alphabet or Unicode block.
+func sequenceMatches(runes []rune) []Match {
+	var matches []Match
+	n := len(runes)
+	i := 0
+	for i < n-1 {
+		delta := runes[i+1] - runes[i]
+		if (delta != 1 && delta != -1) || sequenceAlphabet(runes[i]) == "" || sequenceAlphabet(runes[i]) != sequenceAlphabet(runes[i+1]) {
+			i++
+			continue
+		}
+		j := i + 1
+		for j+1 < n && runes[j+1]-runes[j] == delta && sequenceAlphabet(runes[j+1]) == sequenceAlphabet(runes[i]) {
+			j++
+		}
+		if j-i+1 >= minSequenceLength {
+			matches = append(matches, Match{
+				Pattern:      PatternSequence,
+				I:            i,
+				J:            j,
+				Token:        string(runes[i : j+1]),
+				SequenceName: sequenceAlphabet(runes[i]),
+				Ascending:    delta > 0,
+			})
+		}
+		i = j
+	}
+	return matches
+}
+
+// sequenceScripts are the scripts, besides ASCII, whose code points run in alphabetical order.
+var sequenceScripts = []struct {
+	name  string
+	table *unicode.RangeTable
+}{
+	{"latin", unicode.Latin},
+	{"greek", unicode.Greek},
+	{"cyrillic", unicode.Cyrillic},
+	{"armenian", unicode.Armenian},
+	{"hebrew", unicode.Hebrew},
+	{"arabic", unicode.Arabic},
+	{"devanagari", unicode.Devanagari},
+	{"thai", unicode.Thai},
+	{"hiragana", unicode.Hiragana},
+	{"katakana", unicode.Katakana},
+	{"hangul", unicode.Hangul},
+}
+
+// sequenceAlphabet names the alphabet a character belongs to for sequence detection, e.g.
+// "lower" for ASCII letters or "greek-lower" for Greek ones, or "" when it belongs to none.
+func sequenceAlphabet(r rune) string {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return "lower"
+	case r >= 'A' && r <= 'Z':
+		return "upper"
+	case r >= '0' && r <= '9':
+		return "digits"
+	case r < unicode.MaxASCII:
+		return ""
+	}
+
+	for _, script := range sequenceScripts {
+		if !unicode.Is(script.table, r) {
+			continue
+		}
+		switch {
+		case unicode.IsDigit(r):
+			return script.name + "-digits"
+		case unicode.IsUpper(r):
+			return script.name + "-upper"
+		case unicode.IsLower(r):
+			return script.name + "-lower"
+		case unicode.IsLetter(r):
+			return script.name
+		}
+		return ""
+	}
+	if unicode.IsDigit(r) {
+		return "digits-other"
+	}
+	return ""
+}
+
+func sequenceGuesses(match *Match) float64 {
+	runes := []rune(match.Token)
+	var base float64
+	switch first := runes[0]; {
+	case first == 'a' || first == 'A' || first == 'z' || first == 'Z' || first == '0' || first == '1' || first == '9':
+		// Sequences starting at an obvious end of the alphabet are tried first.
+		base = 4
+	case unicode.IsDigit(first):
+		base = 10
+	default:
+		base = 26
+	}
+	if !match.Ascending {
+		base *= 2
+	}
+	return base * float64(len(runes))
+}
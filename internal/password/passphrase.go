@@ -0,0 +1,125 @@
+package password
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Mode tells whether a password was evaluated as a passphrase or as a password.
+type Mode string
+
+const (
+	ModePassword   Mode = "password"
+	ModePassphrase Mode = "passphrase"
+)
+
+const (
+	defaultPassphraseMinWords = 4
+	// defaultPassphraseWordlistSize is the size of a diceware word list, one word per five dice.
+	defaultPassphraseWordlistSize = 7776
+	// minPassphraseWordLength keeps strings of single letters such as "a b c d" out.
+	minPassphraseWordLength = 2
+)
+
+// PassphraseSettings returns the number of words from which a password counts as a passphrase
+// and the word list size assumed for every word, applying the defaults. A negative minimum
+// means passphrases are evaluated like any other password.
+func (p Policy) PassphraseSettings() (minWords, wordlistSize int) {
+	minWords, wordlistSize = p.PassphraseMinWords, p.PassphraseWordlistSize
+	if minWords == 0 {
+		minWords = defaultPassphraseMinWords
+	}
+	if wordlistSize == 0 {
+		wordlistSize = defaultPassphraseWordlistSize
+	}
+	return minWords, wordlistSize
+}
+
+// passphraseSeparator reports the characters that separate the words of a passphrase.
+func passphraseSeparator(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune("-_.,;:+/|~", r)
+}
+
+// passphraseWords splits the password into its words if it is a passphrase: at least
+// minWords words of letters only, separated by spaces or punctuation.
+func passphraseWords(password string, minWords int) ([]string, bool) {
+	if minWords < 0 {
+		return nil, false
+	}
+	words := strings.FieldsFunc(password, passphraseSeparator)
+	if len(words) < minWords || len(words) < 2 {
+		return nil, false
+	}
+	for _, word := range words {
+		if Length(word) < minPassphraseWordLength {
+			return nil, false
+		}
+		for _, r := range word {
+			if !unicode.IsLetter(r) && !unicode.IsMark(r) {
+				return nil, false
+			}
+		}
+	}
+	return words, true
+}
+
+// passphrase applies the passphrase settings of the policy to the password.
+func (e *Evaluator) passphrase(password string) ([]string, bool) {
+	minWords, _ := e.policy.PassphraseSettings()
+	return passphraseWords(password, minWords)
+}
+
+// passphraseSequence is the search space of an attacker who knows the word list and the
+// number of words, together with one match per word explaining it. Words the estimator
+// guesses faster than a pick from the list, such as common words or names, count with their
+// own estimate. The separators after a word belong to its match, so the matches cover the
+// whole password; separators cost no guesses of their own.
+func passphraseSequence(password string, wordlistSize int, estimate func(word string) Estimate) (float64, []Match) {
+	runes := []rune(password)
+	perWord := math.Log10(float64(wordlistSize))
+	total := 0.0
+	var sequence []Match
+	for i := 0; i < len(runes); {
+		start := i
+		for i < len(runes) && passphraseSeparator(runes[i]) {
+			i++
+		}
+		wordStart := i
+		for i < len(runes) && !passphraseSeparator(runes[i]) {
+			i++
+		}
+		word := string(runes[wordStart:i])
+		for i < len(runes) && passphraseSeparator(runes[i]) {
+			i++
+		}
+		if len(sequence) > 0 {
+			start = wordStart
+		}
+
+		match := Match{Pattern: PatternPassphraseWord, Rank: wordlistSize}
+		guessesLog10 := perWord
+		if wordEstimate := estimate(word); wordEstimate.GuessesLog10 < perWord {
+			guessesLog10 = wordEstimate.GuessesLog10
+			match = Match{Pattern: PatternPassphraseWord}
+			if len(wordEstimate.Sequence) == 1 {
+				match = wordEstimate.Sequence[0]
+			}
+		}
+		match.I, match.J = start, i-1
+		match.Token = string(runes[start:i])
+		match.Guesses = math.Pow(10, guessesLog10)
+		sequence = append(sequence, match)
+		total += guessesLog10
+	}
+	return total, sequence
+}
+
+// missingPassphraseWords returns how many randomly chosen words the passphrase needs to reach
+// the strong threshold.
+func missingPassphraseWords(guessesLog10 float64, wordlistSize int, strong float64) int {
+	if guessesLog10 >= strong {
+		return 0
+	}
+	return int(math.Ceil((strong - guessesLog10) / math.Log10(float64(wordlistSize))))
+}
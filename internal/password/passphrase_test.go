@@ -0,0 +1,73 @@
+package password
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestPassphrasesAreRatedByWords(t *testing.T) {
+	for _, name := range []string{"bsi-orp4", "pci-dss-4"} {
+		policy, _ := Preset(name)
+		evaluator, err := NewEvaluator(policy)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		assessment := evaluator.Assess("gravel tundra pivot lumen quiver")
+		if assessment.Mode != ModePassphrase || assessment.Words != 5 {
+			t.Fatalf("%s: expected a passphrase of 5 words, got %v %d", name, assessment.Mode, assessment.Words)
+		}
+		if assessment.Strength != StrengthStrong || len(assessment.Findings) != 0 {
+			t.Fatalf("%s: expected a strong passphrase without findings, got %v %+v", name, assessment.Strength, assessment.Findings)
+		}
+		if want := 5 * math.Log10(defaultPassphraseWordlistSize); math.Abs(assessment.GuessesLog10-want) > 1e-9 {
+			t.Fatalf("%s: expected 5 diceware words to need 10^%.2f guesses, got 10^%.2f", name, want, assessment.GuessesLog10)
+		}
+	}
+}
+
+func TestPassphraseModeDetection(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for password, mode := range map[string]Mode{
+		"gravel-tundra-pivot-lumen": ModePassphrase,
+		"Gravel.Tundra.Pivot.Lumen": ModePassphrase,
+		"gravel tundra pivot":       ModePassword,
+		"gravel tundra pivot 2024":  ModePassword,
+		"a b c d e f":               ModePassword,
+		"Sommer2024!":               ModePassword,
+	} {
+		if got := evaluator.Assess(password).Mode; got != mode {
+			t.Fatalf("%q: expected %s mode, got %s", password, mode, got)
+		}
+	}
+
+	common := evaluator.Assess("liebe sonne sommer haus")
+	random := evaluator.Assess("gravel tundra pivot lumen")
+	if common.GuessesLog10 >= random.GuessesLog10 {
+		t.Fatalf("expected common words to be guessed faster, got 10^%.2f and 10^%.2f", common.GuessesLog10, random.GuessesLog10)
+	}
+	if last := common.Suggestions[len(common.Suggestions)-1]; last.Code != "suggestion.passphrase_words" {
+		t.Fatalf("expected the passphrase to be extended by words, got %+v", common.Suggestions)
+	}
+}
+
+func TestPassphraseModeCanBeDisabled(t *testing.T) {
+	policy, _ := Preset("bsi-orp4")
+	policy.PassphraseMinWords = -1
+	evaluator, err := NewEvaluator(policy)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assessment := evaluator.Assess("gravel tundra pivot lumen quiver")
+	if assessment.Mode != ModePassword || assessment.Strength != StrengthWeak {
+		t.Fatalf("expected composition rules to apply, got %v %v", assessment.Mode, assessment.Strength)
+	}
+
+	var policyErr *PolicyError
+	if err := (Policy{MinLength: 8, PassphraseMinWords: 1}).Validate(); !errors.As(err, &policyErr) || policyErr.Field != "passphrase_min_words" {
+		t.Fatalf("expected passphrase_min_words to be rejected, got %v", err)
+	}
+}
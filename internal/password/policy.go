@@ -0,0 +1,389 @@
+package password
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// BreachHandling decides how a password found in breach data affects the assessment.
+type BreachHandling string
+
+const (
+	// BreachWarn reports breached passwords without changing their strength.
+	BreachWarn BreachHandling = "warn"
+	// BreachReject rates breached passwords as weak.
+	BreachReject BreachHandling = "reject"
+	// BreachIgnore skips the breach lookup entirely.
+	BreachIgnore BreachHandling = "ignore"
+)
+
+// Guessability thresholds (log10 of the estimated guesses) separating the strength levels
+// when a policy does not set its own. 10^8 guesses withstand online attacks; 10^10 guesses
+// resist offline slow-hash attacks.
+const (
+	moderateGuessesLog10 = 8
+	strongGuessesLog10   = 10
+)
+
+// BannedList is an external list of passwords that must not be used.
+type BannedList interface {
+	Name() string
+	Contains(password string) bool
+}
+
+// Policy describes the password policy enforced by the evaluator. Zero values disable a
+// rule, except for the guessability thresholds and breach handling, which fall back to
+// their defaults.
+type Policy struct {
+	Name        string `json:"name,omitempty"`
+	Version     string `json:"version,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	MinLength int `json:"min_length"`
+	MaxLength int `json:"max_length,omitempty"`
+	// RequiredClasses maps a character class to the minimum number of characters from it.
+	RequiredClasses map[CharacterClass]int `json:"required_classes,omitempty"`
+	// MinClassTypes is the number of distinct classes among lower, upper, digit and special.
+	MinClassTypes int `json:"min_class_types,omitempty"`
+	// NoClassHints drops the advisory findings for every class the password does not use.
+	NoClassHints        bool   `json:"no_class_hints,omitempty"`
+	ForbiddenCharacters string `json:"forbidden_characters,omitempty"`
+	// DenyPatterns are regular expressions no password may match; when AllowPatterns is
+	// set, every password must match at least one of them.
+	DenyPatterns  []string `json:"deny_patterns,omitempty"`
+	AllowPatterns []string `json:"allow_patterns,omitempty"`
+
+	ModerateGuessesLog10 float64        `json:"moderate_guesses_log10,omitempty"`
+	StrongGuessesLog10   float64        `json:"strong_guesses_log10,omitempty"`
+	Breach               BreachHandling `json:"breach,omitempty"`
+
+	// PassphraseMinWords is the number of words, made of letters and separated by spaces or
+	// punctuation, from which a password is evaluated as a passphrase: by its number of words
+	// and PassphraseWordlistSize instead of character classes. Zero applies the default of
+	// four words; a negative value disables passphrase evaluation.
+	PassphraseMinWords int `json:"passphrase_min_words,omitempty"`
+	// PassphraseWordlistSize is the number of words an attacker tries for every word of a
+	// passphrase; zero applies the diceware list size of 7776.
+	PassphraseWordlistSize int `json:"passphrase_wordlist_size,omitempty"`
+
+	// KeyboardLayouts names the layouts checked for keyboard walks; empty checks all KeyboardLayouts.
+	KeyboardLayouts []string `json:"keyboard_layouts,omitempty"`
+	// OrganizationTerms are company, product or location names no password may contain.
+	OrganizationTerms []string `json:"organization_terms,omitempty"`
+	// BannedLists are consulted in order; the first list containing the password is reported.
+	BannedLists []BannedList `json:"-"`
+	// Dictionaries add word lists, e.g. for further languages, to the built-in German and
+	// English dictionaries.
+	Dictionaries []Dictionary `json:"-"`
+
+	// Rules enables or disables rules by code; rules not listed are enabled.
+	Rules map[string]bool `json:"rules,omitempty"`
+	// Registry holds custom rules run alongside the built-in ones.
+	Registry *RuleRegistry `json:"-"`
+}
+
+// Thresholds returns the moderate and strong guessability thresholds, applying the defaults.
+func (p Policy) Thresholds() (moderate, strong float64) {
+	moderate, strong = p.ModerateGuessesLog10, p.StrongGuessesLog10
+	if moderate == 0 {
+		moderate = moderateGuessesLog10
+	}
+	if strong == 0 {
+		strong = strongGuessesLog10
+	}
+	return moderate, strong
+}
+
+// BreachHandling returns how breached passwords are treated, defaulting to BreachWarn.
+func (p Policy) BreachHandling() BreachHandling {
+	if p.Breach == "" {
+		return BreachWarn
+	}
+	return p.Breach
+}
+
+// PolicyError reports an invalid policy setting together with the field it concerns.
+type PolicyError struct {
+	// Field is the JSON name of the offending setting, such as "max_length".
+	Field string
+	Err   error
+}
+
+func (e *PolicyError) Error() string {
+	return e.Field + ": " + e.Err.Error()
+}
+
+func (e *PolicyError) Unwrap() error {
+	return e.Err
+}
+
+func invalidField(field, format string, args ...any) error {
+	return &PolicyError{Field: field, Err: fmt.Errorf(format, args...)}
+}
+
+// Validate reports the first inconsistency in the policy as a *PolicyError.
+func (p Policy) Validate() error {
+	if p.MinLength <= 0 {
+		return invalidField("min_length", "minimum length must be greater than zero")
+	}
+	if p.MaxLength != 0 && p.MaxLength < p.MinLength {
+		return invalidField("max_length", "maximum length %d is below the minimum length %d", p.MaxLength, p.MinLength)
+	}
+	for class, count := range p.RequiredClasses {
+		if _, err := ParseCharacterClass(string(class)); err != nil {
+			return &PolicyError{Field: "required_classes", Err: err}
+		}
+		if count < 0 {
+			return invalidField("required_classes", "required count for %s must not be negative", class)
+		}
+	}
+	if p.MinClassTypes < 0 || p.MinClassTypes > len(classTypes) {
+		return invalidField("min_class_types", "must be between 0 and %d", len(classTypes))
+	}
+	if _, err := compilePatterns(p.DenyPatterns); err != nil {
+		return &PolicyError{Field: "deny_patterns", Err: err}
+	}
+	if _, err := compilePatterns(p.AllowPatterns); err != nil {
+		return &PolicyError{Field: "allow_patterns", Err: err}
+	}
+	moderate, strong := p.Thresholds()
+	if moderate < 0 {
+		return invalidField("moderate_guesses_log10", "threshold %g must not be negative", moderate)
+	}
+	if strong < moderate {
+		return invalidField("strong_guesses_log10", "threshold %g is below the moderate threshold %g", strong, moderate)
+	}
+	if p.PassphraseMinWords == 1 {
+		return invalidField("passphrase_min_words", "a passphrase needs at least 2 words")
+	}
+	if p.PassphraseWordlistSize < 0 || p.PassphraseWordlistSize == 1 {
+		return invalidField("passphrase_wordlist_size", "word list size %d must be at least 2", p.PassphraseWordlistSize)
+	}
+	switch p.BreachHandling() {
+	case BreachWarn, BreachReject, BreachIgnore:
+	default:
+		return invalidField("breach", "unknown breach handling: %s", p.Breach)
+	}
+	if len(p.KeyboardLayouts) > 0 {
+		if _, err := keyboardGraphs(p.KeyboardLayouts); err != nil {
+			return &PolicyError{Field: "keyboard_layouts", Err: err}
+		}
+	}
+	for code := range p.Rules {
+		if _, builtin := builtinRuleOrder[code]; !builtin && (p.Registry == nil || !p.Registry.Has(code)) {
+			return invalidField("rules", "unknown rule %q", code)
+		}
+	}
+	return nil
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// classTypes are the classes hinted at unless Policy.NoClassHints is set; their number bounds
+// Policy.MinClassTypes.
+var classTypes = []CharacterClass{ClassLower, ClassUpper, ClassDigit, ClassSpecial}
+
+// commonScripts are tried first when looking up the script of a letter.
+var commonScripts = []string{"Latin", "Cyrillic", "Greek", "Han", "Hiragana", "Katakana", "Hangul", "Arabic", "Hebrew", "Devanagari", "Thai"}
+
+// classCounts counts the characters of each class; letters count towards ClassLetter as well.
+// Combining marks belong to the class of the character they modify.
+func classCounts(password string) map[CharacterClass]int {
+	counts := make(map[CharacterClass]int, len(classTypes)+2)
+	previous := ClassSpecial
+	for _, r := range password {
+		if unicode.IsMark(r) {
+			counts[previous]++
+			continue
+		}
+		previous = runeClass(r)
+		counts[previous]++
+		if unicode.IsLetter(r) {
+			counts[ClassLetter]++
+		}
+	}
+	return counts
+}
+
+// classTypeCount measures the diversity of the password by Unicode script: digits and
+// special characters count once each, letters once per script and case, so Latin upper
+// and lower case count as two types while Han, Hiragana and Katakana count as three.
+func classTypeCount(password string) int {
+	type letterType struct {
+		script string
+		class  CharacterClass
+	}
+	letters := make(map[letterType]struct{})
+	others := make(map[CharacterClass]struct{})
+	for _, r := range password {
+		if unicode.IsMark(r) {
+			continue
+		}
+		class := runeClass(r)
+		if !unicode.IsLetter(r) {
+			others[class] = struct{}{}
+			continue
+		}
+		letters[letterType{script: letterScript(r), class: class}] = struct{}{}
+	}
+	return len(letters) + len(others)
+}
+
+// letterScript returns the name of the Unicode script the letter belongs to.
+func letterScript(r rune) string {
+	for _, name := range commonScripts {
+		if unicode.Is(unicode.Scripts[name], r) {
+			return name
+		}
+	}
+	for name, table := range unicode.Scripts {
+		if unicode.Is(table, r) {
+			return name
+		}
+	}
+	return ""
+}
+
+// lengthFindings checks the length limits of the policy.
+func (e *Evaluator) lengthFindings(password string) []Finding {
+	var findings []Finding
+	policy := e.policy
+
+	length := Length(password)
+	if length < policy.MinLength {
+		findings = append(findings, Finding{
+			Code:        "length.minimum",
+			Message:     "password is shorter than the minimum required length",
+			Severity:    SeverityError,
+			Requirement: "length",
+		})
+	}
+	if policy.MaxLength > 0 && length > policy.MaxLength {
+		findings = append(findings, Finding{
+			Code:        "length.maximum",
+			Message:     fmt.Sprintf("password is longer than the maximum of %d characters", policy.MaxLength),
+			Severity:    SeverityError,
+			Requirement: "length",
+		})
+	}
+
+	return findings
+}
+
+// charsetFindings checks the character-class and forbidden-character rules of the policy.
+func (e *Evaluator) charsetFindings(password string) []Finding {
+	var findings []Finding
+	policy := e.policy
+
+	// Passphrases are rated by their words; composition rules do not apply to them.
+	if _, ok := e.passphrase(password); ok {
+		return forbiddenCharacterFindings(password, policy.ForbiddenCharacters)
+	}
+
+	counts := classCounts(password)
+	required := make([]CharacterClass, 0, len(policy.RequiredClasses))
+	for class := range policy.RequiredClasses {
+		required = append(required, class)
+	}
+	sort.Slice(required, func(a, b int) bool { return required[a] < required[b] })
+	for _, class := range required {
+		if want := policy.RequiredClasses[class]; counts[class] < want {
+			findings = append(findings, Finding{
+				Code:        "charset." + class.findingName(),
+				Message:     fmt.Sprintf("add at least %d %s character(s)", want, class.findingName()),
+				Severity:    SeverityError,
+				Requirement: "character_sets",
+			})
+		}
+	}
+
+	// Scripts without case cannot provide upper and lower case letters.
+	caselessOnly := counts[ClassCaseless] > 0 && counts[ClassUpper]+counts[ClassLower] == 0
+	for _, class := range classTypes {
+		if counts[class] > 0 {
+			continue
+		}
+		if caselessOnly && (class == ClassUpper || class == ClassLower) {
+			continue
+		}
+		if _, isRequired := policy.RequiredClasses[class]; !policy.NoClassHints && !isRequired {
+			findings = append(findings, Finding{
+				Code:        "charset." + class.findingName(),
+				Message:     fmt.Sprintf("add at least one %s character", class.findingName()),
+				Severity:    SeverityWarn,
+				Requirement: "character_sets",
+			})
+		}
+	}
+	if policy.MinClassTypes > 0 && classTypeCount(password) < policy.MinClassTypes {
+		findings = append(findings, Finding{
+			Code:        "charset.diversity",
+			Message:     fmt.Sprintf("use at least %d character types: digits, special characters and letters, counted per script and case", policy.MinClassTypes),
+			Severity:    SeverityError,
+			Requirement: "character_sets",
+		})
+	}
+
+	return append(findings, forbiddenCharacterFindings(password, policy.ForbiddenCharacters)...)
+}
+
+func forbiddenCharacterFindings(password, forbidden string) []Finding {
+	idx := strings.IndexAny(password, forbidden)
+	if forbidden == "" || idx < 0 {
+		return nil
+	}
+	return []Finding{{
+		Code:        "charset.forbidden",
+		Message:     fmt.Sprintf("password contains the forbidden character %q", []rune(password[idx:])[0]),
+		Severity:    SeverityError,
+		Requirement: "character_sets",
+	}}
+}
+
+// patternPolicyFindings checks the deny and allow patterns of the policy.
+func (e *Evaluator) patternPolicyFindings(password string) []Finding {
+	var findings []Finding
+	policy := e.policy
+
+	for idx, re := range e.denyPatterns {
+		if re.MatchString(password) {
+			findings = append(findings, Finding{
+				Code:        "policy.denied",
+				Message:     fmt.Sprintf("password matches the denied pattern %q", policy.DenyPatterns[idx]),
+				Severity:    SeverityError,
+				Requirement: "patterns",
+			})
+		}
+	}
+	if len(e.allowPatterns) > 0 {
+		allowed := false
+		for _, re := range e.allowPatterns {
+			if re.MatchString(password) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			findings = append(findings, Finding{
+				Code:        "policy.not_allowed",
+				Message:     "password matches none of the allowed patterns",
+				Severity:    SeverityError,
+				Requirement: "patterns",
+			})
+		}
+	}
+	return findings
+}
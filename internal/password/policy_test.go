@@ -0,0 +1,205 @@
+package password
+
+import (
+	"errors"
+	"testing"
+)
+
+func hasFinding(findings []Finding, code string) bool {
+	for _, finding := range findings {
+		if finding.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPresetsAreValid(t *testing.T) {
+	for _, name := range PresetNames() {
+		preset, ok := Preset(name)
+		if !ok {
+			t.Fatalf("preset %s not found", name)
+		}
+		if err := preset.Validate(); err != nil {
+			t.Fatalf("preset %s is invalid: %v", name, err)
+		}
+	}
+	if _, ok := Preset("unknown"); ok {
+		t.Fatal("expected unknown preset to be missing")
+	}
+}
+
+func TestPresetReturnsCopy(t *testing.T) {
+	preset, _ := Preset("pci-dss-4")
+	preset.RequiredClasses[ClassDigit] = 5
+	again, _ := Preset("pci-dss-4")
+	if again.RequiredClasses[ClassDigit] != 1 {
+		t.Fatal("expected presets to be isolated from callers")
+	}
+}
+
+func TestPolicyRules(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{
+		MinLength:           8,
+		MaxLength:           20,
+		RequiredClasses:     map[CharacterClass]int{ClassDigit: 2, ClassLetter: 1},
+		MinClassTypes:       3,
+		ForbiddenCharacters: " ;",
+		DenyPatterns:        []string{`(?i)acme`},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		password string
+		code     string
+	}{
+		{"Kx9#mQ2v!", ""},
+		{"Kx9#mQ2v!Kx9#mQ2v!Kx9", "length.maximum"},
+		{"Kx9#mQzv!", "charset.numeric"},
+		{"982#714!5", "charset.alphabetic"},
+		{"kx9mq2vzp", "charset.diversity"},
+		{"Kx9#mQ2 v!", "charset.forbidden"},
+		{"Kx9#ACME2v!", "policy.denied"},
+	}
+	for _, tc := range cases {
+		findings := evaluator.Assess(tc.password).Findings
+		if tc.code == "" {
+			for _, finding := range findings {
+				if finding.Severity == SeverityError {
+					t.Fatalf("%s: unexpected error finding %+v", tc.password, finding)
+				}
+			}
+			continue
+		}
+		if !hasFinding(findings, tc.code) {
+			t.Fatalf("%s: expected %s finding, got %+v", tc.password, tc.code, findings)
+		}
+	}
+}
+
+func TestPolicyAllowPatterns(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 4, AllowPatterns: []string{`^[0-9]+$`}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasFinding(evaluator.Assess("abc123").Findings, "policy.not_allowed") {
+		t.Fatal("expected password outside the allow list to be reported")
+	}
+	if hasFinding(evaluator.Assess("839201").Findings, "policy.not_allowed") {
+		t.Fatal("expected password inside the allow list to pass")
+	}
+}
+
+func TestPolicyThresholdsDecideStrength(t *testing.T) {
+	lenient, err := NewEvaluator(Policy{MinLength: 8, ModerateGuessesLog10: 4, StrongGuessesLog10: 6})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	strict, err := NewEvaluator(Policy{MinLength: 8, ModerateGuessesLog10: 14, StrongGuessesLog10: 16})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strength := lenient.Assess("Kiwi#4821x").Strength; strength == StrengthWeak {
+		t.Fatalf("expected lenient thresholds to accept the password, got %s", strength)
+	}
+	if strength := strict.Assess("Kiwi#4821x").Strength; strength != StrengthWeak {
+		t.Fatalf("expected strict thresholds to reject the password, got %s", strength)
+	}
+}
+
+func TestPolicyValidateRejectsInconsistencies(t *testing.T) {
+	cases := []Policy{
+		{MinLength: 12, MaxLength: 8},
+		{MinLength: 8, RequiredClasses: map[CharacterClass]int{"emoji": 1}},
+		{MinLength: 8, MinClassTypes: 5},
+		{MinLength: 8, DenyPatterns: []string{"("}},
+		{MinLength: 8, ModerateGuessesLog10: 12, StrongGuessesLog10: 10},
+		{MinLength: 8, Breach: "maybe"},
+	}
+	for _, policy := range cases {
+		if err := policy.Validate(); err == nil {
+			t.Fatalf("expected %+v to be invalid", policy)
+		}
+	}
+
+	var policyErr *PolicyError
+	if err := (Policy{MinLength: 12, MaxLength: 8}).Validate(); !errors.As(err, &policyErr) || policyErr.Field != "max_length" {
+		t.Fatalf("expected max_length policy error, got %v", err)
+	}
+}
+
+func TestClassHintsAreOnByDefault(t *testing.T) {
+	hinted, _ := NewEvaluator(Policy{MinLength: 8})
+	plain, _ := NewEvaluator(Policy{MinLength: 8, NoClassHints: true})
+	if !hasFinding(hinted.Assess("correct horse battery").Findings, "charset.uppercase") {
+		t.Fatal("expected class hint for a zero-value policy")
+	}
+	if hasFinding(plain.Assess("correct horse battery").Findings, "charset.uppercase") {
+		t.Fatal("expected no class hint with NoClassHints")
+	}
+}
+
+func TestCaselessScriptsSkipCaseHints(t *testing.T) {
+	policy, _ := Preset(DefaultPolicyName)
+	evaluator, err := NewEvaluator(policy)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, passphrase := range []string{
+		"東京タワーの夜景はとてもきれい",
+		"שלום עולם הים הכחול והשמש",
+		"مرحبا بالعالم الجميل والسماء",
+	} {
+		assessment := evaluator.Assess(passphrase)
+		if hasFinding(assessment.Findings, "charset.uppercase") || hasFinding(assessment.Findings, "charset.lowercase") {
+			t.Fatalf("%s: unexpected case hints: %+v", passphrase, assessment.Findings)
+		}
+		if assessment.Strength != StrengthStrong {
+			t.Fatalf("%s: expected strong, got %s", passphrase, assessment.Strength)
+		}
+	}
+	if !hasFinding(evaluator.Assess("東京tower夜景2024!x").Findings, "charset.uppercase") {
+		t.Fatal("expected uppercase hint when the password contains cased letters")
+	}
+}
+
+func TestClassTypeCountMeasuresScripts(t *testing.T) {
+	cases := map[string]int{
+		"Password1!":      4,
+		"東京タワーの夜景":        4, // Han, Katakana, Hiragana and the prolonged sound mark (Common)
+		"שלוםעולם2024":    2,
+		"Moscow Москва 1": 6,
+		"مَرْحَبًا":       1,
+	}
+	for password, want := range cases {
+		if got := classTypeCount(password); got != want {
+			t.Fatalf("classTypeCount(%q) = %d, want %d", password, got, want)
+		}
+	}
+}
+
+func TestDiversityCountsCaselessScripts(t *testing.T) {
+	policy, _ := Preset("bsi-orp4")
+	evaluator, err := NewEvaluator(policy)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hasFinding(evaluator.Assess("東京タワーの夜景はとてもきれい").Findings, "charset.diversity") {
+		t.Fatal("expected Han, Katakana and Hiragana to satisfy three character types")
+	}
+	if !hasFinding(evaluator.Assess("שלוםעולםהיםהכחול2024").Findings, "charset.diversity") {
+		t.Fatal("expected Hebrew letters and digits to count as two character types")
+	}
+}
+
+func TestCombiningMarksFollowTheirBase(t *testing.T) {
+	counts := classCounts("مَرْحَبًا")
+	if counts[ClassSpecial] != 0 || counts[ClassCaseless] == 0 {
+		t.Fatalf("expected Arabic vowel marks to count as caseless letters, got %v", counts)
+	}
+	if class, err := ParseCharacterClass("caseless"); err != nil || class != ClassCaseless {
+		t.Fatalf("expected caseless class, got %q, %v", class, err)
+	}
+}
@@ -0,0 +1,85 @@
+package password
+
+import (
+	"sort"
+	"strings"
+)
+
+// DefaultPolicyName names the preset used when no policy is selected.
+const DefaultPolicyName = "default"
+
+// presets are the built-in policies. Each mirrors the password requirements of its standard
+// as far as they can be checked on the password alone; MFA-dependent variants use the
+// stricter single-factor rules.
+var presets = map[string]Policy{
+	DefaultPolicyName: {
+		Name:        DefaultPolicyName,
+		Version:     "1",
+		Description: "Guessability-based rating with advisory character-class hints",
+		MinLength:   12,
+		Breach:      BreachWarn,
+	},
+	"nist-800-63b": {
+		Name:         "nist-800-63b",
+		Version:      "SP 800-63B-4",
+		Description:  "NIST SP 800-63B: length over composition, blocklist and breach screening, at least 15 characters for single-factor use",
+		MinLength:    15,
+		NoClassHints: true,
+		Breach:       BreachReject,
+	},
+	"bsi-orp4": {
+		Name:          "bsi-orp4",
+		Version:       "IT-Grundschutz Edition 2023",
+		Description:   "BSI IT-Grundschutz ORP.4: sufficiently long and complex passwords, compromised passwords must be changed",
+		MinLength:     12,
+		MinClassTypes: 3,
+		NoClassHints:  true,
+		Breach:        BreachReject,
+	},
+	"pci-dss-4": {
+		Name:        "pci-dss-4",
+		Version:     "4.0",
+		Description: "PCI DSS 4.0 requirement 8.3.6: at least 12 characters with numeric and alphabetic characters",
+		MinLength:   12,
+		RequiredClasses: map[CharacterClass]int{
+			ClassLetter: 1,
+			ClassDigit:  1,
+		},
+		NoClassHints: true,
+		Breach:       BreachWarn,
+	},
+	"cis": {
+		Name:         "cis",
+		Version:      "CIS Controls v8",
+		Description:  "CIS Controls v8 safeguard 5.2: at least 14 characters for password-only accounts, breached passwords rejected",
+		MinLength:    14,
+		NoClassHints: true,
+		Breach:       BreachReject,
+	},
+}
+
+// Preset returns a copy of the built-in policy with the given name.
+func Preset(name string) (Policy, bool) {
+	preset, ok := presets[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return Policy{}, false
+	}
+	if preset.RequiredClasses != nil {
+		classes := make(map[CharacterClass]int, len(preset.RequiredClasses))
+		for class, count := range preset.RequiredClasses {
+			classes[class] = count
+		}
+		preset.RequiredClasses = classes
+	}
+	return preset, true
+}
+
+// PresetNames lists the names of the built-in policies in alphabetical order.
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
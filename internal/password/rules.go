@@ -0,0 +1,216 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Rule is a check the evaluator runs against every password. Organisations add their own
+// checks by registering rules in Policy.Registry instead of changing the evaluator.
+type Rule interface {
+	// Code identifies the rule when ordering it and when a policy enables or disables it.
+	Code() string
+	// Check inspects the NFKC-normalised password.
+	Check(password string, evaluation EvaluationContext) RuleResult
+}
+
+// RuleResult is the outcome of a rule. EntropyAdjustment is added to the estimated entropy in
+// bits; a negative value lowers the guessability of a pattern the estimator does not know.
+type RuleResult struct {
+	Findings          []Finding
+	EntropyAdjustment float64
+}
+
+// NewRule adapts a function to the Rule interface.
+func NewRule(code string, check func(password string, evaluation EvaluationContext) RuleResult) Rule {
+	return ruleFunc{code: code, check: check}
+}
+
+type ruleFunc struct {
+	code  string
+	check func(string, EvaluationContext) RuleResult
+}
+
+func (r ruleFunc) Code() string {
+	return r.code
+}
+
+func (r ruleFunc) Check(password string, evaluation EvaluationContext) RuleResult {
+	return r.check(password, evaluation)
+}
+
+// findingsRule adapts a check that only reports findings.
+func findingsRule(code string, check func(string, EvaluationContext) []Finding) Rule {
+	return NewRule(code, func(password string, evaluation EvaluationContext) RuleResult {
+		return RuleResult{Findings: check(password, evaluation)}
+	})
+}
+
+// RuleRegistry holds rules together with the order in which the evaluator runs them.
+type RuleRegistry struct {
+	entries []ruleEntry
+}
+
+type ruleEntry struct {
+	rule  Rule
+	order int
+}
+
+// NewRuleRegistry returns an empty registry.
+func NewRuleRegistry() *RuleRegistry {
+	return &RuleRegistry{}
+}
+
+// Register adds the rule. Rules run in ascending order; rules with the same order run in
+// registration order. The built-in rules run at orders 100 to 1200, see BuiltinRuleCodes.
+func (r *RuleRegistry) Register(rule Rule, order int) error {
+	if rule == nil {
+		return errors.New("rule cannot be nil")
+	}
+	code := strings.TrimSpace(rule.Code())
+	if code == "" {
+		return errors.New("rule code cannot be empty")
+	}
+	if r.Has(code) {
+		return fmt.Errorf("rule %s is already registered", code)
+	}
+	r.entries = append(r.entries, ruleEntry{rule: rule, order: order})
+	sort.SliceStable(r.entries, func(a, b int) bool { return r.entries[a].order < r.entries[b].order })
+	return nil
+}
+
+// Has reports whether a rule with the code is registered.
+func (r *RuleRegistry) Has(code string) bool {
+	for _, entry := range r.entries {
+		if entry.rule.Code() == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Codes lists the codes of the registered rules in execution order.
+func (r *RuleRegistry) Codes() []string {
+	codes := make([]string, len(r.entries))
+	for i, entry := range r.entries {
+		codes[i] = entry.rule.Code()
+	}
+	return codes
+}
+
+// enabled returns the rules in execution order, leaving out those the policy disables.
+func (r *RuleRegistry) enabled(settings map[string]bool) []Rule {
+	rules := make([]Rule, 0, len(r.entries))
+	for _, entry := range r.entries {
+		if enabled, ok := settings[entry.rule.Code()]; ok && !enabled {
+			continue
+		}
+		rules = append(rules, entry.rule)
+	}
+	return rules
+}
+
+// builtinRuleOrder maps the codes of the built-in rules to their order. Custom rules choose
+// an order between them to run at a particular point.
+var builtinRuleOrder = map[string]int{
+	"length":      100,
+	"charset":     200,
+	"patterns":    300,
+	"characters":  400,
+	"confusables": 500,
+	"common":      600,
+	"banned":      700,
+	"context":     800,
+	"keyboard":    900,
+	"sequences":   1000,
+	"dates":       1100,
+	"mangling":    1200,
+}
+
+// BuiltinRuleCodes lists the codes of the built-in rules in execution order; they run at
+// orders 100, 200 and so on.
+func BuiltinRuleCodes() []string {
+	codes := make([]string, 0, len(builtinRuleOrder))
+	for code := range builtinRuleOrder {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(a, b int) bool { return builtinRuleOrder[codes[a]] < builtinRuleOrder[codes[b]] })
+	return codes
+}
+
+// builtinRules returns the evaluator's own checks.
+func (e *Evaluator) builtinRules() []Rule {
+	return []Rule{
+		findingsRule("length", func(password string, _ EvaluationContext) []Finding {
+			return e.lengthFindings(password)
+		}),
+		findingsRule("charset", func(password string, _ EvaluationContext) []Finding {
+			return e.charsetFindings(password)
+		}),
+		findingsRule("patterns", func(password string, _ EvaluationContext) []Finding {
+			return e.patternPolicyFindings(password)
+		}),
+		findingsRule("characters", func(password string, _ EvaluationContext) []Finding {
+			return characterFindings(password)
+		}),
+		findingsRule("confusables", func(password string, _ EvaluationContext) []Finding {
+			return confusableFindings(password)
+		}),
+		findingsRule("common", func(password string, _ EvaluationContext) []Finding {
+			return commonPasswordFindings(password)
+		}),
+		findingsRule("banned", func(password string, _ EvaluationContext) []Finding {
+			return e.bannedListFindings(password)
+		}),
+		findingsRule("context", func(password string, evaluation EvaluationContext) []Finding {
+			return contextFindings(password, evaluation.terms(e.policy.OrganizationTerms))
+		}),
+		// The pattern rules report from the matches of the estimate instead of matching again.
+		findingsRule("keyboard", func(_ string, evaluation EvaluationContext) []Finding {
+			return keyboardFindings(evaluation.matches)
+		}),
+		findingsRule("sequences", func(_ string, evaluation EvaluationContext) []Finding {
+			return patternFindings(evaluation.matches)
+		}),
+		findingsRule("dates", func(_ string, evaluation EvaluationContext) []Finding {
+			return dateFindings(evaluation.matches)
+		}),
+		findingsRule("mangling", func(_ string, evaluation EvaluationContext) []Finding {
+			return manglingFindings(evaluation.matches)
+		}),
+	}
+}
+
+func commonPasswordFindings(password string) []Finding {
+	match, ok := MatchCommonPassword(password)
+	if !ok {
+		return nil
+	}
+	message := "password is commonly used and easily guessable"
+	if len(match.Transformations) > 0 {
+		message = fmt.Sprintf("password is derived from the common password %q (%s) and easily guessable", match.BaseWord, strings.Join(match.Transformations, ", "))
+	}
+	return []Finding{{
+		Code:        "password.common",
+		Message:     message,
+		Severity:    SeverityError,
+		Requirement: "common_passwords",
+	}}
+}
+
+// bannedListFindings reports the first banned list containing the password.
+func (e *Evaluator) bannedListFindings(password string) []Finding {
+	for _, list := range e.policy.BannedLists {
+		if list.Contains(password) {
+			return []Finding{{
+				Code:        "password.banned",
+				Message:     fmt.Sprintf("password appears on the banned password list %q", list.Name()),
+				Severity:    SeverityError,
+				Requirement: "banned_list:" + list.Name(),
+			}}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package password
+
+import (
+	"strings"
+	"testing"
+)
+
+func ticketRule() Rule {
+	return NewRule("acme.tickets", func(password string, _ EvaluationContext) RuleResult {
+		if !strings.Contains(strings.ToUpper(password), "ACME-") {
+			return RuleResult{}
+		}
+		return RuleResult{
+			Findings: []Finding{{
+				Code:        "acme.ticket",
+				Message:     "password contains a ticket number",
+				Severity:    SeverityWarn,
+				Requirement: "acme",
+			}},
+			EntropyAdjustment: -20,
+		}
+	})
+}
+
+func TestCustomRulesRunInOrder(t *testing.T) {
+	registry := NewRuleRegistry()
+	if err := registry.Register(ticketRule(), 150); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := registry.Register(ticketRule(), 150); err == nil {
+		t.Fatal("expected duplicate rule code to be rejected")
+	}
+	plain, _ := NewEvaluator(Policy{MinLength: 30})
+	custom, err := NewEvaluator(Policy{MinLength: 30, Registry: registry})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	password := "lantern harbor acme-4711 tide"
+	before := plain.Assess(password)
+	after := custom.Assess(password)
+	codes := make([]string, len(after.Findings))
+	for i, finding := range after.Findings {
+		codes[i] = finding.Code
+	}
+	if len(codes) < 3 || codes[0] != "length.minimum" || codes[1] != "acme.ticket" || !strings.HasPrefix(codes[2], "charset.") {
+		t.Fatalf("expected the custom finding between length and charset findings, got %v", codes)
+	}
+	if diff := before.EntropyBits - after.EntropyBits; diff < 19.9 || diff > 20.1 {
+		t.Fatalf("expected the entropy adjustment of 20 bits, got %.2f", diff)
+	}
+}
+
+func TestPolicyDisablesRulesByCode(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 8, Rules: map[string]bool{"common": false}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if hasFinding(evaluator.Assess("password123").Findings, "password.common") {
+		t.Fatal("expected the disabled common rule not to run")
+	}
+
+	if err := (Policy{MinLength: 8, Rules: map[string]bool{"acme.tickets": true}}).Validate(); err == nil {
+		t.Fatal("expected unknown rule code to be rejected")
+	}
+	registry := NewRuleRegistry()
+	_ = registry.Register(ticketRule(), 1200)
+	if err := (Policy{MinLength: 8, Rules: map[string]bool{"acme.tickets": false}, Registry: registry}).Validate(); err != nil {
+		t.Fatalf("expected registered custom rule to be configurable, got %v", err)
+	}
+
+	clash := NewRuleRegistry()
+	_ = clash.Register(NewRule("common", func(string, EvaluationContext) RuleResult { return RuleResult{} }), 50)
+	if _, err := NewEvaluator(Policy{MinLength: 8, Registry: clash}); err == nil {
+		t.Fatal("expected custom rule reusing a built-in code to be rejected")
+	}
+}
+
+func TestBuiltinRuleCodesAreOrdered(t *testing.T) {
+	codes := BuiltinRuleCodes()
+	if len(codes) != len(builtinRuleOrder) || codes[0] != "length" || codes[len(codes)-1] != "mangling" {
+		t.Fatalf("unexpected built-in rule codes %v", codes)
+	}
+}
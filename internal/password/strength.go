@@ -1,8 +1,10 @@
 package password
 
 import (
-	"errors"
-	"unicode"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
 )
 
 // Strength represents the qualitative strength of a password.
@@ -17,6 +19,36 @@ const (
 	StrengthStrong Strength = "strong"
 )
 
+// ParseStrength converts a textual strength level into a Strength.
+func ParseStrength(value string) (Strength, error) {
+	switch Strength(strings.ToLower(strings.TrimSpace(value))) {
+	case StrengthWeak:
+		return StrengthWeak, nil
+	case StrengthModerate:
+		return StrengthModerate, nil
+	case StrengthStrong:
+		return StrengthStrong, nil
+	default:
+		return "", fmt.Errorf("unknown strength: %s", value)
+	}
+}
+
+// AtLeast reports whether the strength meets or exceeds the other level.
+func (s Strength) AtLeast(other Strength) bool {
+	return s.rank() >= other.rank()
+}
+
+func (s Strength) rank() int {
+	switch s {
+	case StrengthStrong:
+		return 2
+	case StrengthModerate:
+		return 1
+	default:
+		return 0
+	}
+}
+
 // Severity represents the severity level of a policy finding.
 type Severity string
 
@@ -31,112 +63,213 @@ type Finding struct {
 	Message     string
 	Severity    Severity
 	Requirement string
+
+	// i and j are the inclusive rune offsets of the part of the password the finding is
+	// about; located is false for findings about the whole password.
+	i, j    int
+	located bool
 }
 
-// Policy describes the password policy enforced by the evaluator.
-type Policy struct {
-	MinLength int
+// at locates the finding at the inclusive rune offsets i to j.
+func (f Finding) at(i, j int) Finding {
+	f.i, f.j, f.located = i, j, true
+	return f
+}
+
+// Assessment is the complete result of evaluating a password.
+type Assessment struct {
+	Strength Strength
+	// Score places the password on a 0-100 scale, see Score.
+	Score        int
+	Findings     []Finding
+	Guesses      float64
+	GuessesLog10 float64
+	EntropyBits  float64
+	// Length counts grapheme clusters of the normalised password.
+	Length int
+	// Classes lists the character classes used, in the order lower, upper, caseless, digit, special.
+	Classes []CharacterClass
+	Matches []Match
+	// Suggestions propose concrete fixes, weakest part of the password first.
+	Suggestions []Suggestion
+	// Mode tells whether the password was evaluated as a passphrase; Words counts the words
+	// of a passphrase.
+	Mode  Mode
+	Words int
 }
 
 // Evaluator performs password strength checks based on the configured policy.
 type Evaluator struct {
-	policy Policy
+	policy        Policy
+	estimator     *estimator
+	denyPatterns  []*regexp.Regexp
+	allowPatterns []*regexp.Regexp
+	rules         []Rule
 }
 
-// NewEvaluator constructs a new Evaluator instance.
+// NewEvaluator constructs a new Evaluator instance running the built-in rules and the rules
+// registered in Policy.Registry, minus those the policy disables.
 func NewEvaluator(policy Policy) (*Evaluator, error) {
-	if policy.MinLength <= 0 {
-		return nil, errors.New("minimum length must be greater than zero")
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	// Validate has already compiled the patterns successfully.
+	deny, _ := compilePatterns(policy.DenyPatterns)
+	allow, _ := compilePatterns(policy.AllowPatterns)
+
+	est := newEstimator()
+	est.dictionaries = append(est.dictionaries, rankedDictionaries(policy.Dictionaries)...)
+	if len(policy.KeyboardLayouts) > 0 {
+		graphs, err := keyboardGraphs(policy.KeyboardLayouts)
+		if err != nil {
+			return nil, err
+		}
+		est.graphs = graphs
+	}
+	evaluator := &Evaluator{policy: policy, estimator: est, denyPatterns: deny, allowPatterns: allow}
+
+	registry := NewRuleRegistry()
+	for _, rule := range evaluator.builtinRules() {
+		if err := registry.Register(rule, builtinRuleOrder[rule.Code()]); err != nil {
+			return nil, err
+		}
+	}
+	if policy.Registry != nil {
+		for _, entry := range policy.Registry.entries {
+			if err := registry.Register(entry.rule, entry.order); err != nil {
+				return nil, err
+			}
+		}
 	}
-	return &Evaluator{policy: policy}, nil
+	evaluator.rules = registry.enabled(policy.Rules)
+	return evaluator, nil
+}
+
+// Policy returns the policy the evaluator enforces.
+func (e *Evaluator) Policy() Policy {
+	return e.policy
 }
 
 // Evaluate analyses the supplied password and returns its strength alongside policy findings.
 func (e *Evaluator) Evaluate(password string) (Strength, []Finding) {
-	findings := make([]Finding, 0, 4)
+	assessment := e.Assess(password)
+	return assessment.Strength, assessment.Findings
+}
 
-	length := len(password)
-	if length < e.policy.MinLength {
-		findings = append(findings, Finding{
-			Code:        "length.minimum",
-			Message:     "password is shorter than the minimum required length",
-			Severity:    SeverityError,
-			Requirement: "length",
-		})
-	}
+// Assess analyses the password without user or service context.
+func (e *Evaluator) Assess(password string) Assessment {
+	return e.AssessWithContext(password, EvaluationContext{})
+}
 
-	var hasUpper, hasLower, hasDigit, hasSpecial bool
-	for _, r := range password {
-		switch {
-		case unicode.IsUpper(r):
-			hasUpper = true
-		case unicode.IsLower(r):
-			hasLower = true
-		case unicode.IsDigit(r):
-			hasDigit = true
-		case unicode.IsSymbol(r) || unicode.IsPunct(r):
-			hasSpecial = true
-		}
-	}
+// AssessWithContext analyses the password and returns its strength, derived from the estimated
+// number of guesses, together with the policy findings. Terms from the evaluation context count
+// as dictionary words for the estimate and are reported when the password contains them.
+// Findings with error severity, such as unmet policy rules, make the password weak. The
+// password is NFKC-normalised first and lengths count grapheme clusters. The rules run in
+// registry order and their entropy adjustments are applied to the estimate. Passphrases are
+// estimated by their number of words and the word list size and exempt from class rules.
+func (e *Evaluator) AssessWithContext(password string, evaluation EvaluationContext) Assessment {
+	findings := make([]Finding, 0, 4)
+	password = Normalise(password)
 
-	if !hasUpper {
-		findings = append(findings, Finding{
-			Code:        "charset.uppercase",
-			Message:     "add at least one uppercase character",
-			Severity:    SeverityWarn,
-			Requirement: "character_sets",
-		})
+	terms := evaluation.terms(e.policy.OrganizationTerms)
+	estimator := e.estimator.withUserInputs(terms)
+	estimate := estimator.estimate(password)
+
+	var adjustment float64
+	evaluation.matches = estimate.matches
+	for _, rule := range e.rules {
+		result := rule.Check(password, evaluation)
+		findings = append(findings, result.Findings...)
+		adjustment += result.EntropyAdjustment
 	}
-	if !hasLower {
-		findings = append(findings, Finding{
-			Code:        "charset.lowercase",
-			Message:     "add at least one lowercase character",
-			Severity:    SeverityWarn,
-			Requirement: "character_sets",
-		})
+	mode, missingWords := ModePassword, 0
+	words, passphrase := e.passphrase(password)
+	moderate, strong := e.policy.Thresholds()
+	if passphrase {
+		mode = ModePassphrase
+		_, wordlistSize := e.policy.PassphraseSettings()
+		// Attackers pick whichever is cheaper: words from the list or the patterns found. The
+		// sequence follows, so segments explain the estimate actually used.
+		if guessesLog10, sequence := passphraseSequence(password, wordlistSize, estimator.estimate); guessesLog10 < estimate.GuessesLog10 {
+			estimate.GuessesLog10 = guessesLog10
+			estimate.EntropyBits = guessesLog10 / math.Log10(2)
+			estimate.Guesses = math.Pow(10, guessesLog10)
+			estimate.Sequence = sequence
+		}
+		missingWords = missingPassphraseWords(estimate.GuessesLog10, wordlistSize, strong)
 	}
-	if !hasDigit {
-		findings = append(findings, Finding{
-			Code:        "charset.numeric",
-			Message:     "add at least one numeric character",
-			Severity:    SeverityWarn,
-			Requirement: "character_sets",
-		})
+	if adjustment != 0 {
+		estimate.EntropyBits = math.Max(0, estimate.EntropyBits+adjustment)
+		estimate.GuessesLog10 = estimate.EntropyBits * math.Log10(2)
+		estimate.Guesses = math.Pow(10, estimate.GuessesLog10)
 	}
-	if !hasSpecial {
-		findings = append(findings, Finding{
-			Code:        "charset.special",
-			Message:     "add at least one special character",
-			Severity:    SeverityWarn,
-			Requirement: "character_sets",
-		})
+	assessment := Assessment{
+		Guesses:      estimate.Guesses,
+		GuessesLog10: estimate.GuessesLog10,
+		EntropyBits:  estimate.EntropyBits,
+		Matches:      estimate.Sequence,
+		Mode:         mode,
+		Words:        len(words),
 	}
 
-	if IsCommonPassword(password) {
+	if estimate.GuessesLog10 < moderate {
 		findings = append(findings, Finding{
-			Code:        "password.common",
-			Message:     "password is commonly used and easily guessable",
-			Severity:    SeverityError,
-			Requirement: "common_passwords",
+			Code:        "guesses.low",
+			Message:     fmt.Sprintf("password can be guessed in about 10^%.1f attempts", estimate.GuessesLog10),
+			Severity:    SeverityWarn,
+			Requirement: "guessability",
 		})
 	}
 
 	mandatoryFailures := false
-	warnings := 0
 	for _, finding := range findings {
 		if finding.Severity == SeverityError {
 			mandatoryFailures = true
-		} else {
-			warnings++
 		}
 	}
 
+	assessment.Findings = findings
 	switch {
-	case mandatoryFailures:
-		return StrengthWeak, findings
-	case warnings == 0 && length >= e.policy.MinLength+4 && hasUpper && hasLower && hasDigit && hasSpecial:
-		return StrengthStrong, findings
+	case mandatoryFailures || estimate.GuessesLog10 < moderate:
+		assessment.Strength = StrengthWeak
+	case estimate.GuessesLog10 >= strong:
+		assessment.Strength = StrengthStrong
+	default:
+		assessment.Strength = StrengthModerate
+	}
+	assessment.Score = Score(estimate.GuessesLog10, assessment.Strength, e.policy)
+	assessment.Suggestions = suggest(assessment.Strength, Segments(estimate.Sequence, findings), estimate.Sequence, missingWords)
+	assessment.Length = Length(password)
+	counts := classCounts(password)
+	for _, class := range []CharacterClass{ClassLower, ClassUpper, ClassCaseless, ClassDigit, ClassSpecial} {
+		if counts[class] > 0 {
+			assessment.Classes = append(assessment.Classes, class)
+		}
+	}
+	return assessment
+}
+
+// Score maps the estimated guesses onto a 0-100 scale that trends smoothly while staying
+// within the band of the strength level: weak 0-39, moderate 40-69 and strong 70-100, where
+// 100 is reached eight orders of magnitude above the strong threshold. A password rated weak
+// because of a failed rule never scores above the weak band.
+func Score(guessesLog10 float64, strength Strength, policy Policy) int {
+	moderate, strong := policy.Thresholds()
+	// scale maps value from [low, high] onto [from, to], clamping at both ends.
+	scale := func(value, low, high, from, to float64) int {
+		fraction := 1.0
+		if high > low {
+			fraction = math.Max(0, math.Min(1, (value-low)/(high-low)))
+		}
+		return int(math.Round(from + fraction*(to-from)))
+	}
+	switch strength {
+	case StrengthStrong:
+		return scale(guessesLog10, strong, strong+8, 70, 100)
+	case StrengthModerate:
+		return scale(guessesLog10, moderate, strong, 40, 69)
 	default:
-		return StrengthModerate, findings
+		return scale(guessesLog10, 0, moderate, 0, 39)
 	}
 }
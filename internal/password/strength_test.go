@@ -1,6 +1,9 @@
 package password
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+)
 
 func TestNewEvaluatorValidation(t *testing.T) {
 	if _, err := NewEvaluator(Policy{MinLength: 0}); err == nil {
@@ -59,3 +62,101 @@ func TestEvaluatorCommonPassword(t *testing.T) {
 		t.Fatalf("expected common password finding")
 	}
 }
+
+func TestStrengthAtLeast(t *testing.T) {
+	if !StrengthStrong.AtLeast(StrengthModerate) || StrengthWeak.AtLeast(StrengthModerate) {
+		t.Fatalf("unexpected strength ordering")
+	}
+	if _, err := ParseStrength("excellent"); err == nil {
+		t.Fatalf("expected error for unknown strength")
+	}
+}
+
+func TestNewEvaluatorRejectsUnknownKeyboardLayout(t *testing.T) {
+	if _, err := NewEvaluator(Policy{MinLength: 8, KeyboardLayouts: []string{"dvorak"}}); err == nil {
+		t.Fatal("expected error for unknown keyboard layout")
+	}
+}
+
+type stubBannedList struct {
+	name  string
+	words map[string]bool
+}
+
+func (l stubBannedList) Name() string { return l.name }
+
+func (l stubBannedList) Contains(password string) bool { return l.words[password] }
+
+func TestEvaluatorReportsBannedList(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 8, BannedLists: []BannedList{
+		stubBannedList{name: "first", words: map[string]bool{}},
+		stubBannedList{name: "corporate", words: map[string]bool{"Acme!Sommer#2024x": true}},
+	}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	assessment := evaluator.Assess("Acme!Sommer#2024x")
+	if assessment.Strength != StrengthWeak {
+		t.Fatalf("expected banned password to be weak, got %s", assessment.Strength)
+	}
+	for _, finding := range assessment.Findings {
+		if finding.Code == "password.banned" {
+			if finding.Requirement != "banned_list:corporate" {
+				t.Fatalf("unexpected requirement: %s", finding.Requirement)
+			}
+			return
+		}
+	}
+	t.Fatalf("expected password.banned finding, got %+v", assessment.Findings)
+}
+
+func TestScoreStaysWithinStrengthBands(t *testing.T) {
+	policy := Policy{MinLength: 8}
+	cases := []struct {
+		log10    float64
+		strength Strength
+		min, max int
+	}{
+		{0, StrengthWeak, 0, 0},
+		{6, StrengthWeak, 1, 39},
+		{12, StrengthWeak, 39, 39},
+		{8, StrengthModerate, 40, 40},
+		{9.5, StrengthModerate, 41, 69},
+		{10, StrengthStrong, 70, 70},
+		{14, StrengthStrong, 71, 99},
+		{30, StrengthStrong, 100, 100},
+	}
+	for _, tc := range cases {
+		if score := Score(tc.log10, tc.strength, policy); score < tc.min || score > tc.max {
+			t.Fatalf("Score(%g, %s) = %d, want %d-%d", tc.log10, tc.strength, score, tc.min, tc.max)
+		}
+	}
+
+	evaluator, _ := NewEvaluator(policy)
+	assessment := evaluator.Assess("Harbor7lantern!")
+	if assessment.Length != 15 || len(assessment.Classes) != 4 {
+		t.Fatalf("expected length 15 and four classes, got %d %v", assessment.Length, assessment.Classes)
+	}
+	if assessment.Score != Score(assessment.GuessesLog10, assessment.Strength, policy) {
+		t.Fatalf("expected assessment score to follow Score, got %d", assessment.Score)
+	}
+}
+
+func TestLowGuessesFindingMatchesTheEstimate(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assessment := evaluator.Assess("Sommer2024!")
+	want := fmt.Sprintf("password can be guessed in about 10^%.1f attempts", assessment.GuessesLog10)
+	for _, finding := range assessment.Findings {
+		if finding.Code == "guesses.low" {
+			if finding.Message != want {
+				t.Fatalf("expected %q, got %q", want, finding.Message)
+			}
+			return
+		}
+	}
+	t.Fatalf("expected a guesses.low finding, got %+v", assessment.Findings)
+}
@@ -0,0 +1,94 @@
+package password
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Suggestion is a concrete change that makes the password harder to guess, in contrast to
+// findings, which only state what is wrong.
+type Suggestion struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Start and End are the 1-based, inclusive positions of the part the suggestion is about;
+	// both are zero for suggestions about the whole password.
+	Start int `json:"start,omitempty"`
+	End   int `json:"end,omitempty"`
+}
+
+// suggest proposes fixes for the weakest segments first. Strong passwords only get suggestions
+// for segments that triggered a finding; weaker ones are also advised to switch to a passphrase,
+// since adding a special character or a digit to a guessable word barely changes the estimate.
+// missingWords is the number of words a passphrase lacks to become strong.
+func suggest(strength Strength, segments []Segment, matches []Match, missingWords int) []Suggestion {
+	order := make([]int, len(segments))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return segments[order[a]].GuessesLog10 < segments[order[b]].GuessesLog10 })
+
+	var suggestions []Suggestion
+	for _, i := range order {
+		segment := segments[i]
+		if strength == StrengthStrong && len(segment.Findings) == 0 {
+			continue
+		}
+		code, message := segmentSuggestion(matches[i])
+		if code == "" {
+			continue
+		}
+		suggestions = append(suggestions, Suggestion{Code: code, Message: message, Start: segment.Start, End: segment.End})
+	}
+	switch {
+	case strength != StrengthStrong && missingWords > 0:
+		suggestions = append(suggestions, Suggestion{
+			Code:    "suggestion.passphrase_words",
+			Message: fmt.Sprintf("add %d more randomly chosen word(s) to the passphrase; each word multiplies the guesses by the size of the word list", missingWords),
+		})
+	case strength != StrengthStrong:
+		suggestions = append(suggestions, Suggestion{
+			Code:    "suggestion.passphrase",
+			Message: "use a longer passphrase of four or more unrelated words; length adds far more guesses than special characters",
+		})
+	}
+	return suggestions
+}
+
+// segmentSuggestion returns the fix for the pattern of the match; random parts need none.
+func segmentSuggestion(match Match) (string, string) {
+	switch match.Pattern {
+	case PatternDictionary:
+		if match.DictionaryName == userInputsDictionary {
+			return "suggestion.remove_personal", fmt.Sprintf("remove %q, it is derived from your account, the service or your organisation", match.Token)
+		}
+		message := fmt.Sprintf("replace %q, %q is among the most common passwords", match.Token, match.MatchedWord)
+		switch {
+		case match.ManglingRule != "":
+			message = fmt.Sprintf("replace %q, cracking rules turn %q into it within the first guesses", match.Token, match.MatchedWord)
+		case len(match.Parts) > 1:
+			message = fmt.Sprintf("replace %q, compounds of common words such as %s are tried early", match.Token, quoteJoin(match.Parts))
+		case match.DictionaryName != commonPasswordDictionary.name:
+			message = fmt.Sprintf("replace %q, it is a common word or name (%s)", match.Token, match.DictionaryName)
+		}
+		switch {
+		case match.L33t:
+			message += "; swapping letters for digits or symbols does not hide it"
+		case match.Reversed:
+			message += "; writing it backwards does not hide it"
+		}
+		return "suggestion.replace_word", message
+	case PatternDate:
+		if match.Month == 0 {
+			return "suggestion.remove_date", fmt.Sprintf("remove the year %q, years are among the first guesses", match.Token)
+		}
+		return "suggestion.remove_date", fmt.Sprintf("remove the date %q, dates are among the first guesses", match.Token)
+	case PatternSpatial:
+		return "suggestion.break_keyboard_walk", fmt.Sprintf("break the keyboard walk %q with characters that are not next to each other on the keyboard", match.Token)
+	case PatternSequence:
+		return "suggestion.break_sequence", fmt.Sprintf("replace the sequence %q, each of its characters follows from the first", match.Token)
+	case PatternRepeat:
+		return "suggestion.avoid_repeat", fmt.Sprintf("replace the repetition %q, repeating %q barely adds guesses", match.Token, match.BaseToken)
+	default:
+		return "", ""
+	}
+}
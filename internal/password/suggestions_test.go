@@ -0,0 +1,40 @@
+package password
+
+import "testing"
+
+func TestSuggestionsAddressWeakestSegmentsFirst(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 8, KeyboardLayouts: []string{"qwertz"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assessment := evaluator.Assess("Sommer2024!qwertz")
+
+	var codes []string
+	for _, suggestion := range assessment.Suggestions {
+		codes = append(codes, suggestion.Code)
+	}
+	want := []string{"suggestion.remove_date", "suggestion.break_keyboard_walk"}
+	if len(codes) != len(want) || codes[0] != want[0] || codes[1] != want[1] {
+		t.Fatalf("expected %v first, got %v", want, codes)
+	}
+	if date := assessment.Suggestions[0]; date.Start != 7 || date.End != 10 {
+		t.Fatalf("expected the year at positions 7-10, got %+v", date)
+	}
+}
+
+func TestSuggestionsForStrongPasswords(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assessment := evaluator.Assess("vJ7#qLx2!mZp9@rT")
+	if assessment.Strength != StrengthStrong || len(assessment.Suggestions) != 0 {
+		t.Fatalf("expected no suggestions for a strong random password, got %v %+v", assessment.Strength, assessment.Suggestions)
+	}
+
+	weak := evaluator.Assess("P@ssw0rd")
+	last := weak.Suggestions[len(weak.Suggestions)-1]
+	if weak.Suggestions[0].Code != "suggestion.replace_word" || last.Code != "suggestion.passphrase" {
+		t.Fatalf("expected the word to be replaced and a passphrase suggested, got %+v", weak.Suggestions)
+	}
+}
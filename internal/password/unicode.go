@@ -0,0 +1,78 @@
+package password
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/rivo/uniseg"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalise applies Unicode NFKC normalisation as recommended by NIST SP 800-63B, so that
+// composed and decomposed umlauts or full-width letters are treated as the same password.
+func Normalise(password string) string {
+	return norm.NFKC.String(password)
+}
+
+// Length counts the user-perceived characters (grapheme clusters) of the password, so that
+// "ä" counts once whether it is typed as one code point or as "a" plus a combining mark.
+func Length(password string) int {
+	return uniseg.GraphemeClusterCount(password)
+}
+
+// characterFindings reports control characters, which are usually pasted by accident and
+// break login forms, and characters that cannot be typed on keyboards or input methods.
+func characterFindings(password string) []Finding {
+	var findings []Finding
+	var control, untypeable []rune
+	var firstControl, firstUntypeable int
+	position := 0
+	for i, r := range password {
+		position++
+		switch {
+		case unicode.IsControl(r):
+			if len(control) == 0 {
+				firstControl = position
+			}
+			control = append(control, r)
+		case isUntypeable(password[i:], r):
+			if len(untypeable) == 0 {
+				firstUntypeable = position
+			}
+			untypeable = append(untypeable, r)
+		}
+	}
+	if len(control) > 0 {
+		findings = append(findings, Finding{
+			Code:        "charset.control",
+			Message:     fmt.Sprintf("password contains %d control character(s), the first is U+%04X at position %d", len(control), control[0], firstControl),
+			Severity:    SeverityError,
+			Requirement: "printable_characters",
+		})
+	}
+	if len(untypeable) > 0 {
+		findings = append(findings, Finding{
+			Code:        "charset.untypeable",
+			Message:     fmt.Sprintf("password contains %d character(s) that cannot be typed reliably, the first is U+%04X at position %d", len(untypeable), untypeable[0], firstUntypeable),
+			Severity:    SeverityWarn,
+			Requirement: "printable_characters",
+		})
+	}
+	return findings
+}
+
+// isUntypeable reports invalid UTF-8, unassigned and private-use code points, surrogates and
+// noncharacters. rest is the password from r onwards and tells invalid bytes from U+FFFD.
+func isUntypeable(rest string, r rune) bool {
+	if r == utf8.RuneError {
+		_, size := utf8.DecodeRuneInString(rest)
+		return size <= 1
+	}
+	switch {
+	case unicode.Is(unicode.Co, r), unicode.Is(unicode.Cs, r), unicode.Is(unicode.Noncharacter_Code_Point, r):
+		return true
+	}
+	// Assigned code points belong to one of the general categories.
+	return !unicode.In(r, unicode.L, unicode.M, unicode.N, unicode.P, unicode.S, unicode.Z, unicode.C)
+}
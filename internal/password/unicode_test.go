@@ -0,0 +1,65 @@
+package password
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLengthCountsGraphemeClusters(t *testing.T) {
+	cases := map[string]int{
+		"\u00c4rger":     5,
+		"A\u0308rger":    5,
+		"q\u0303q\u0303": 2,
+		"\U0001F469\u200d\U0001F469\u200d\U0001F467 family": 8,
+	}
+	for input, want := range cases {
+		if got := Length(input); got != want {
+			t.Fatalf("Length(%q) = %d, want %d", input, got, want)
+		}
+	}
+}
+
+func TestMinLengthCountsUserPerceivedCharacters(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 12})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Eleven characters that stay two code points each after normalisation.
+	short := strings.Repeat("q\u0303", 11)
+	if !hasFinding(evaluator.Assess(short).Findings, "length.minimum") {
+		t.Fatal("expected combining sequences to count as one character each")
+	}
+}
+
+func TestAssessNormalisesBeforeEvaluation(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !hasFinding(evaluator.Assess("\uff30\uff41\uff53\uff53\uff57\uff4f\uff52\uff44\uff11\uff12\uff13").Findings, "password.common") {
+		t.Fatal("expected full-width spelling to be recognised as a common password")
+	}
+	composed := evaluator.Assess("Gr\u00fc\u00dfe aus K\u00f6ln 1987!")
+	decomposed := evaluator.Assess("Gru\u0308\u00dfe aus Ko\u0308ln 1987!")
+	if composed.GuessesLog10 != decomposed.GuessesLog10 || len(composed.Findings) != len(decomposed.Findings) {
+		t.Fatalf("expected NFC and NFD spellings to be assessed identically: %+v vs %+v", composed, decomposed)
+	}
+}
+
+func TestCharacterFindings(t *testing.T) {
+	evaluator, err := NewEvaluator(Policy{MinLength: 8})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	control := evaluator.Assess("Lantern\x07Harbor#42")
+	if !hasFinding(control.Findings, "charset.control") || control.Strength != StrengthWeak {
+		t.Fatalf("expected control character to be rejected, got %+v", control)
+	}
+	private := evaluator.Assess("Lantern\ue000Harbor#42")
+	if !hasFinding(private.Findings, "charset.untypeable") {
+		t.Fatalf("expected private-use character to be reported, got %+v", private.Findings)
+	}
+	if findings := characterFindings("Gr\u00fc\u00dfe aus K\u00f6ln, \u6771\u4eac \U0001F680"); len(findings) != 0 {
+		t.Fatalf("expected ordinary Unicode to pass, got %+v", findings)
+	}
+}
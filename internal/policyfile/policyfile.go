@@ -0,0 +1,406 @@
+// Package policyfile loads password policies from JSON or YAML files. A policy file may
+// extend a preset or another policy file and only states what it changes.
+package policyfile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/vectode/password-checker/internal/password"
+)
+
+// Issue is a single problem in a policy file, located by line and column.
+type Issue struct {
+	Path    string
+	Line    int
+	Column  int
+	Field   string
+	Message string
+}
+
+func (i Issue) String() string {
+	location := fmt.Sprintf("%s:%d:%d", i.Path, i.Line, i.Column)
+	if i.Field == "" {
+		return location + ": " + i.Message
+	}
+	return location + ": " + i.Field + ": " + i.Message
+}
+
+// Error collects every problem found while loading a policy file.
+type Error struct {
+	Issues []Issue
+}
+
+func (e *Error) Error() string {
+	lines := make([]string, len(e.Issues))
+	for i, issue := range e.Issues {
+		lines[i] = issue.String()
+	}
+	return "invalid policy:\n  " + strings.Join(lines, "\n  ")
+}
+
+// Resolve returns the preset with the given name or, when ref names no preset, the policy
+// loaded from the file at ref. The registry holds the custom rules the policy may refer to
+// and may be nil.
+func Resolve(ref string, registry *password.RuleRegistry) (password.Policy, error) {
+	if policy, ok := password.Preset(ref); ok {
+		policy.Registry = registry
+		return policy, nil
+	}
+	if _, err := os.Stat(ref); err != nil {
+		return password.Policy{}, fmt.Errorf("unknown policy %q: neither a preset (%s) nor a readable file", ref, strings.Join(password.PresetNames(), ", "))
+	}
+	return Load(ref, registry)
+}
+
+// Load reads the policy file at path, resolves its extends chain and validates the result
+// against the built-in rules and the custom rules in registry, which may be nil. Problems are
+// reported as an *Error pointing to the offending lines.
+func Load(path string, registry *password.RuleRegistry) (password.Policy, error) {
+	l := &loader{origins: make(map[string]Issue)}
+	policy, err := l.load(path)
+	if err != nil {
+		return password.Policy{}, err
+	}
+	policy.Registry = registry
+	if err := policy.Validate(); err != nil {
+		var policyErr *password.PolicyError
+		if !errors.As(err, &policyErr) {
+			return password.Policy{}, err
+		}
+		issue, ok := l.origins[policyErr.Field]
+		if !ok {
+			issue = l.origins[""]
+		}
+		issue.Field = policyErr.Field
+		issue.Message = policyErr.Err.Error()
+		return password.Policy{}, &Error{Issues: []Issue{issue}}
+	}
+	return policy, nil
+}
+
+type loader struct {
+	// chain holds the files being loaded, outermost first, to detect inheritance cycles.
+	chain []string
+	// origins records where each field was last set; "" locates the top-level file itself.
+	origins map[string]Issue
+}
+
+func (l *loader) load(path string) (password.Policy, error) {
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return password.Policy{}, err
+	}
+	for _, seen := range l.chain {
+		if seen == absolute {
+			return password.Policy{}, fmt.Errorf("policy inheritance cycle: %s", strings.Join(append(l.chain, absolute), " -> "))
+		}
+	}
+	l.chain = append(l.chain, absolute)
+	defer func() { l.chain = l.chain[:len(l.chain)-1] }()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return password.Policy{}, fmt.Errorf("failed to read policy: %w", err)
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return password.Policy{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(document.Content) == 0 {
+		return password.Policy{}, &Error{Issues: []Issue{{Path: path, Line: 1, Column: 1, Message: "policy file is empty"}}}
+	}
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return password.Policy{}, &Error{Issues: []Issue{issueAt(path, root, "", "policy must be a mapping of settings")}}
+	}
+	if len(l.chain) == 1 {
+		l.origins[""] = issueAt(path, root, "", "")
+	}
+
+	var policy password.Policy
+	var issues []Issue
+	if extends := lookup(root, "extends"); extends != nil {
+		parent, err := l.parent(path, extends)
+		if err != nil {
+			var fileErr *Error
+			if !errors.As(err, &fileErr) {
+				return password.Policy{}, err
+			}
+			issues = append(issues, fileErr.Issues...)
+		}
+		policy = parent
+	}
+	// Name, version and description describe this file and are not inherited.
+	policy.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	policy.Version = ""
+	policy.Description = ""
+
+	seen := make(map[string]bool, len(root.Content)/2)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if seen[key.Value] {
+			issues = append(issues, issueAt(path, key, key.Value, "duplicate field"))
+			continue
+		}
+		seen[key.Value] = true
+		if key.Value == "extends" {
+			continue
+		}
+		decode, ok := fields[key.Value]
+		if !ok {
+			issues = append(issues, issueAt(path, key, key.Value, "unknown field"))
+			continue
+		}
+		if err := decode(value, &policy); err != nil {
+			node := value
+			var located *nodeError
+			if errors.As(err, &located) {
+				node = located.node
+			}
+			issues = append(issues, issueAt(path, node, key.Value, err.Error()))
+			continue
+		}
+		l.origins[key.Value] = issueAt(path, value, key.Value, "")
+	}
+	if len(issues) > 0 {
+		return password.Policy{}, &Error{Issues: issues}
+	}
+	return policy, nil
+}
+
+// parent resolves the extends setting: a preset name or a file relative to the extending file.
+func (l *loader) parent(path string, node *yaml.Node) (password.Policy, error) {
+	if node.Kind != yaml.ScalarNode || strings.TrimSpace(node.Value) == "" {
+		return password.Policy{}, &Error{Issues: []Issue{issueAt(path, node, "extends", "expected a preset name or file path")}}
+	}
+	if preset, ok := password.Preset(node.Value); ok {
+		return preset, nil
+	}
+	parentPath := node.Value
+	if !filepath.IsAbs(parentPath) {
+		parentPath = filepath.Join(filepath.Dir(path), parentPath)
+	}
+	if _, err := os.Stat(parentPath); err != nil {
+		return password.Policy{}, &Error{Issues: []Issue{issueAt(path, node, "extends",
+			fmt.Sprintf("%q is neither a preset (%s) nor a readable file", node.Value, strings.Join(password.PresetNames(), ", ")))}}
+	}
+	return l.load(parentPath)
+}
+
+func lookup(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func issueAt(path string, node *yaml.Node, field, message string) Issue {
+	return Issue{Path: path, Line: node.Line, Column: node.Column, Field: field, Message: message}
+}
+
+// nodeError locates a decoding problem inside a value, such as one element of a list.
+type nodeError struct {
+	node    *yaml.Node
+	message string
+}
+
+func (e *nodeError) Error() string {
+	return e.message
+}
+
+func invalid(node *yaml.Node, format string, args ...any) error {
+	return &nodeError{node: node, message: fmt.Sprintf(format, args...)}
+}
+
+// fields decodes each supported setting onto the policy inherited from the parent. Lists
+// replace the inherited value; required_classes is merged class by class.
+var fields = map[string]func(*yaml.Node, *password.Policy) error{
+	"name":        func(n *yaml.Node, p *password.Policy) error { return decodeString(n, &p.Name) },
+	"version":     func(n *yaml.Node, p *password.Policy) error { return decodeString(n, &p.Version) },
+	"description": func(n *yaml.Node, p *password.Policy) error { return decodeString(n, &p.Description) },
+	"min_length":  func(n *yaml.Node, p *password.Policy) error { return decodeInt(n, &p.MinLength) },
+	"max_length":  func(n *yaml.Node, p *password.Policy) error { return decodeInt(n, &p.MaxLength) },
+	"required_classes": func(n *yaml.Node, p *password.Policy) error {
+		return decodeClasses(n, p)
+	},
+	"min_class_types":      func(n *yaml.Node, p *password.Policy) error { return decodeInt(n, &p.MinClassTypes) },
+	"no_class_hints":       func(n *yaml.Node, p *password.Policy) error { return decodeBool(n, &p.NoClassHints) },
+	"forbidden_characters": func(n *yaml.Node, p *password.Policy) error { return decodeString(n, &p.ForbiddenCharacters) },
+	"deny_patterns":        func(n *yaml.Node, p *password.Policy) error { return decodePatterns(n, &p.DenyPatterns) },
+	"allow_patterns":       func(n *yaml.Node, p *password.Policy) error { return decodePatterns(n, &p.AllowPatterns) },
+	"moderate_guesses_log10": func(n *yaml.Node, p *password.Policy) error {
+		return decodeFloat(n, &p.ModerateGuessesLog10)
+	},
+	"strong_guesses_log10": func(n *yaml.Node, p *password.Policy) error {
+		return decodeFloat(n, &p.StrongGuessesLog10)
+	},
+	"passphrase_min_words": func(n *yaml.Node, p *password.Policy) error {
+		return decodeInt(n, &p.PassphraseMinWords)
+	},
+	"passphrase_wordlist_size": func(n *yaml.Node, p *password.Policy) error {
+		return decodeInt(n, &p.PassphraseWordlistSize)
+	},
+	"breach": func(n *yaml.Node, p *password.Policy) error {
+		var value string
+		if err := decodeString(n, &value); err != nil {
+			return err
+		}
+		switch handling := password.BreachHandling(strings.ToLower(value)); handling {
+		case password.BreachWarn, password.BreachReject, password.BreachIgnore:
+			p.Breach = handling
+			return nil
+		}
+		return invalid(n, "expected warn, reject or ignore, got %q", value)
+	},
+	"keyboard_layouts": func(n *yaml.Node, p *password.Policy) error {
+		layouts, err := decodeStrings(n)
+		if err != nil {
+			return err
+		}
+		for i, layout := range layouts {
+			layouts[i] = strings.ToLower(layout)
+			if !knownLayout(layouts[i]) {
+				return invalid(n.Content[i], "unknown keyboard layout %q (available: %s)", layout, strings.Join(password.KeyboardLayouts, ", "))
+			}
+		}
+		p.KeyboardLayouts = layouts
+		return nil
+	},
+	"rules": func(n *yaml.Node, p *password.Policy) error {
+		return decodeRules(n, p)
+	},
+	"organization_terms": func(n *yaml.Node, p *password.Policy) error {
+		terms, err := decodeStrings(n)
+		if err != nil {
+			return err
+		}
+		p.OrganizationTerms = terms
+		return nil
+	},
+}
+
+func decodeString(node *yaml.Node, target *string) error {
+	if node.Kind != yaml.ScalarNode || node.Tag == "!!null" {
+		return invalid(node, "expected a string")
+	}
+	*target = node.Value
+	return nil
+}
+
+func decodeInt(node *yaml.Node, target *int) error {
+	if node.Kind != yaml.ScalarNode || node.Tag != "!!int" {
+		return invalid(node, "expected an integer, got %q", node.Value)
+	}
+	return node.Decode(target)
+}
+
+func decodeFloat(node *yaml.Node, target *float64) error {
+	if node.Kind != yaml.ScalarNode || (node.Tag != "!!int" && node.Tag != "!!float") {
+		return invalid(node, "expected a number, got %q", node.Value)
+	}
+	return node.Decode(target)
+}
+
+func decodeBool(node *yaml.Node, target *bool) error {
+	if node.Kind != yaml.ScalarNode || node.Tag != "!!bool" {
+		return invalid(node, "expected true or false, got %q", node.Value)
+	}
+	return node.Decode(target)
+}
+
+func decodeStrings(node *yaml.Node) ([]string, error) {
+	if node.Kind != yaml.SequenceNode {
+		return nil, invalid(node, "expected a list of strings")
+	}
+	values := make([]string, len(node.Content))
+	for i, item := range node.Content {
+		if err := decodeString(item, &values[i]); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+func decodePatterns(node *yaml.Node, target *[]string) error {
+	patterns, err := decodeStrings(node)
+	if err != nil {
+		return err
+	}
+	for i, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return invalid(node.Content[i], "%v", err)
+		}
+	}
+	*target = patterns
+	return nil
+}
+
+func decodeClasses(node *yaml.Node, policy *password.Policy) error {
+	if node.Kind != yaml.MappingNode {
+		return invalid(node, "expected a mapping from character class to count")
+	}
+	classes := make(map[password.CharacterClass]int, len(policy.RequiredClasses)+len(node.Content)/2)
+	for class, count := range policy.RequiredClasses {
+		classes[class] = count
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		class, err := password.ParseCharacterClass(key.Value)
+		if err != nil {
+			return invalid(key, "%v", err)
+		}
+		var count int
+		if err := decodeInt(value, &count); err != nil {
+			return err
+		}
+		if count < 0 {
+			return invalid(value, "count must not be negative")
+		}
+		if count == 0 {
+			delete(classes, class)
+			continue
+		}
+		classes[class] = count
+	}
+	policy.RequiredClasses = classes
+	return nil
+}
+
+// decodeRules merges the rule settings over the inherited ones. Unknown codes are reported
+// by Validate, which knows the custom rules as well.
+func decodeRules(node *yaml.Node, policy *password.Policy) error {
+	if node.Kind != yaml.MappingNode {
+		return invalid(node, "expected a mapping from rule code to true or false")
+	}
+	rules := make(map[string]bool, len(policy.Rules)+len(node.Content)/2)
+	for code, enabled := range policy.Rules {
+		rules[code] = enabled
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		var enabled bool
+		if err := decodeBool(node.Content[i+1], &enabled); err != nil {
+			return err
+		}
+		rules[node.Content[i].Value] = enabled
+	}
+	policy.Rules = rules
+	return nil
+}
+
+func knownLayout(name string) bool {
+	for _, layout := range password.KeyboardLayouts {
+		if layout == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package policyfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vectode/password-checker/internal/password"
+)
+
+func writePolicy(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestLoadExtendsPresetAndFile(t *testing.T) {
+	dir := t.TempDir()
+	writePolicy(t, dir, "base.yaml", "extends: pci-dss-4\nname: acme\nversion: \"2026.1\"\norganization_terms: [acme]\n")
+	path := writePolicy(t, dir, "finance.json", `{
+  "extends": "base.yaml",
+  "min_length": 16,
+  "required_classes": {"special": 1, "digit": 0}
+}`)
+
+	policy, err := Load(path, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if policy.Name != "finance" || policy.Version != "" {
+		t.Fatalf("expected name and version of the file itself, got %q %q", policy.Name, policy.Version)
+	}
+	if policy.MinLength != 16 || policy.BreachHandling() != password.BreachWarn {
+		t.Fatalf("expected min length override and inherited breach handling, got %+v", policy)
+	}
+	want := map[password.CharacterClass]int{password.ClassLetter: 1, password.ClassSpecial: 1}
+	if len(policy.RequiredClasses) != len(want) {
+		t.Fatalf("expected merged classes %v, got %v", want, policy.RequiredClasses)
+	}
+	for class, count := range want {
+		if policy.RequiredClasses[class] != count {
+			t.Fatalf("expected merged classes %v, got %v", want, policy.RequiredClasses)
+		}
+	}
+	if len(policy.OrganizationTerms) != 1 || policy.OrganizationTerms[0] != "acme" {
+		t.Fatalf("expected inherited organisation terms, got %v", policy.OrganizationTerms)
+	}
+}
+
+func TestLoadReportsIssuesWithLocations(t *testing.T) {
+	path := writePolicy(t, t.TempDir(), "team.yaml", `extends: default
+min_lenght: 14
+required_classes:
+  emoji: 1
+deny_patterns:
+  - "^acme"
+  - "(unclosed"
+no_class_hints: maybe
+`)
+
+	_, err := Load(path, nil)
+	var invalid *Error
+	if !errors.As(err, &invalid) {
+		t.Fatalf("expected *Error, got %v", err)
+	}
+	want := []Issue{
+		{Line: 2, Column: 1, Field: "min_lenght"},
+		{Line: 4, Column: 3, Field: "required_classes"},
+		{Line: 7, Column: 5, Field: "deny_patterns"},
+		{Line: 8, Column: 17, Field: "no_class_hints"},
+	}
+	if len(invalid.Issues) != len(want) {
+		t.Fatalf("expected %d issues, got %v", len(want), invalid.Issues)
+	}
+	for i, issue := range invalid.Issues {
+		if issue.Line != want[i].Line || issue.Column != want[i].Column || issue.Field != want[i].Field {
+			t.Fatalf("issue %d: expected %+v, got %+v", i, want[i], issue)
+		}
+	}
+}
+
+func TestLoadLocatesValidationErrors(t *testing.T) {
+	path := writePolicy(t, t.TempDir(), "short.yaml", "extends: cis\nmax_length: 8\n")
+
+	_, err := Load(path, nil)
+	var invalid *Error
+	if !errors.As(err, &invalid) || len(invalid.Issues) != 1 {
+		t.Fatalf("expected a single issue, got %v", err)
+	}
+	if issue := invalid.Issues[0]; issue.Line != 2 || issue.Field != "max_length" {
+		t.Fatalf("expected max_length on line 2, got %+v", issue)
+	}
+}
+
+func TestLoadDetectsInheritanceCycles(t *testing.T) {
+	dir := t.TempDir()
+	path := writePolicy(t, dir, "a.yaml", "extends: b.yaml\nmin_length: 12\n")
+	writePolicy(t, dir, "b.yaml", "extends: a.yaml\n")
+
+	if _, err := Load(path, nil); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("expected inheritance cycle error, got %v", err)
+	}
+}
+
+func TestResolvePrefersPresets(t *testing.T) {
+	policy, err := Resolve("NIST-800-63B", nil)
+	if err != nil || policy.Name != "nist-800-63b" {
+		t.Fatalf("expected preset, got %+v, %v", policy, err)
+	}
+	if _, err := Resolve("does-not-exist", nil); err == nil {
+		t.Fatal("expected error for unknown policy")
+	}
+}
+
+func TestLoadConfiguresRules(t *testing.T) {
+	dir := t.TempDir()
+	writePolicy(t, dir, "base.yaml", "extends: default\nrules:\n  dates: false\n  keyboard: false\n")
+	path := writePolicy(t, dir, "team.yaml", "extends: base.yaml\nrules:\n  keyboard: true\n")
+
+	policy, err := Load(path, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if enabled, ok := policy.Rules["dates"]; !ok || enabled {
+		t.Fatalf("expected inherited dates rule to stay disabled, got %v", policy.Rules)
+	}
+	if !policy.Rules["keyboard"] {
+		t.Fatalf("expected keyboard rule to be re-enabled, got %v", policy.Rules)
+	}
+
+	typo := writePolicy(t, dir, "typo.yaml", "extends: default\nrules:\n  comon: false\n")
+	var invalid *Error
+	if _, err := Load(typo, nil); !errors.As(err, &invalid) || invalid.Issues[0].Field != "rules" || invalid.Issues[0].Line != 3 {
+		t.Fatalf("expected unknown rule reported on line 3, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package profiles
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/vectode/password-checker/internal/password"
+)
+
+// Profile describes the password rules of a site, selected by label or URL host patterns.
+type Profile struct {
+	Name               string   `json:"name"`
+	Labels             []string `json:"labels"`
+	URLs               []string `json:"urls"`
+	MaxLength          int      `json:"max_length"`
+	AllowedCharset     string   `json:"allowed_charset"`
+	RequiredClasses    []string `json:"required_classes"`
+	ForbiddenSequences []string `json:"forbidden_sequences"`
+}
+
+// Constraints converts the profile into generator constraints.
+func (p Profile) Constraints() password.Constraints {
+	classes := make([]password.CharacterClass, 0, len(p.RequiredClasses))
+	for _, raw := range p.RequiredClasses {
+		// Classes are validated when the profile set is loaded.
+		class, _ := password.ParseCharacterClass(raw)
+		classes = append(classes, class)
+	}
+	return password.Constraints{
+		MaxLength:          p.MaxLength,
+		AllowedCharset:     p.AllowedCharset,
+		RequiredClasses:    classes,
+		ForbiddenSequences: p.ForbiddenSequences,
+	}
+}
+
+func (p Profile) validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return errors.New("profile name cannot be empty")
+	}
+	if len(p.Labels) == 0 && len(p.URLs) == 0 {
+		return fmt.Errorf("profile %s must define at least one label or url pattern", p.Name)
+	}
+	for _, pattern := range append(append([]string(nil), p.Labels...), p.URLs...) {
+		if _, err := path.Match(strings.ToLower(pattern), ""); err != nil {
+			return fmt.Errorf("profile %s: invalid pattern %q: %w", p.Name, pattern, err)
+		}
+	}
+	if p.MaxLength < 0 {
+		return fmt.Errorf("profile %s: max_length must not be negative", p.Name)
+	}
+	for _, class := range p.RequiredClasses {
+		if _, err := password.ParseCharacterClass(class); err != nil {
+			return fmt.Errorf("profile %s: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// Set is an ordered collection of site profiles; the first matching profile wins.
+type Set struct {
+	profiles []Profile
+}
+
+// NewSet validates the profiles and returns them as a Set.
+func NewSet(profiles []Profile) (*Set, error) {
+	for _, profile := range profiles {
+		if err := profile.validate(); err != nil {
+			return nil, err
+		}
+	}
+	return &Set{profiles: profiles}, nil
+}
+
+// Load reads site profiles from a JSON file. A missing file yields an empty set.
+func Load(filePath string) (*Set, error) {
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Set{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read site profiles: %w", err)
+	}
+
+	var payload struct {
+		Profiles []Profile `json:"profiles"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode site profiles: %w", err)
+	}
+	return NewSet(payload.Profiles)
+}
+
+// Match returns the first profile whose label pattern matches the target or whose
+// URL pattern matches the target's host when the target is a URL.
+func (s *Set) Match(target string) (Profile, bool) {
+	label := strings.ToLower(strings.TrimSpace(target))
+	if label == "" {
+		return Profile{}, false
+	}
+	host := hostOf(label)
+
+	for _, profile := range s.profiles {
+		for _, pattern := range profile.Labels {
+			if ok, _ := path.Match(strings.ToLower(pattern), label); ok {
+				return profile, true
+			}
+		}
+		if host == "" {
+			continue
+		}
+		for _, pattern := range profile.URLs {
+			if ok, _ := path.Match(strings.ToLower(hostOf(pattern)), host); ok {
+				return profile, true
+			}
+		}
+	}
+	return Profile{}, false
+}
+
+// hostOf extracts the host from a URL or bare host name such as "login.example.com/path".
+func hostOf(value string) string {
+	if !strings.Contains(value, "://") {
+		value = "https://" + value
+	}
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return ""
+	}
+	return parsed.Hostname()
+}
@@ -0,0 +1,58 @@
+package profiles
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetMatchByLabelAndURL(t *testing.T) {
+	set, err := NewSet([]Profile{
+		{Name: "bank", Labels: []string{"bank*"}, MaxLength: 16},
+		{Name: "example", URLs: []string{"*.example.com"}, RequiredClasses: []string{"digit"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if profile, ok := set.Match("Bank-Online"); !ok || profile.Name != "bank" {
+		t.Fatalf("expected bank profile, got %+v (%v)", profile, ok)
+	}
+	if profile, ok := set.Match("https://login.example.com/signin"); !ok || profile.Name != "example" {
+		t.Fatalf("expected example profile, got %+v (%v)", profile, ok)
+	}
+	if _, ok := set.Match("mail"); ok {
+		t.Fatalf("expected no profile for unrelated label")
+	}
+}
+
+func TestNewSetValidation(t *testing.T) {
+	if _, err := NewSet([]Profile{{Name: "empty"}}); err == nil {
+		t.Fatalf("expected error for profile without patterns")
+	}
+	if _, err := NewSet([]Profile{{Name: "class", Labels: []string{"x"}, RequiredClasses: []string{"emoji"}}}); err == nil {
+		t.Fatalf("expected error for unknown character class")
+	}
+}
+
+func TestLoadMissingFileYieldsEmptySet(t *testing.T) {
+	set, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := set.Match("anything"); ok {
+		t.Fatalf("expected empty set")
+	}
+
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	if err := os.WriteFile(path, []byte(`{"profiles":[{"name":"legacy","labels":["legacy"],"max_length":8}]}`), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	set, err = Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if profile, ok := set.Match("legacy"); !ok || profile.Constraints().MaxLength != 8 {
+		t.Fatalf("expected legacy profile with max length 8")
+	}
+}
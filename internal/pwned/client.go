@@ -2,8 +2,6 @@ package pwned
 
 import (
 	"context"
-	"crypto/sha1"
-	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -50,8 +48,7 @@ func (c *Client) IsBreached(ctx context.Context, password string) (bool, error)
 		return false, errors.New("password must not be empty")
 	}
 
-	hash := sha1.Sum([]byte(password))
-	hexHash := strings.ToUpper(hex.EncodeToString(hash[:]))
+	hexHash := hashPassword(password)
 	prefix := hexHash[:5]
 	suffix := hexHash[5:]
 
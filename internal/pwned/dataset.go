@@ -2,7 +2,6 @@ package pwned
 
 import (
 	"context"
-	"crypto/sha1"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -53,9 +52,7 @@ func (d *Dataset) IsBreached(ctx context.Context, password string) (bool, error)
 			return false, err
 		}
 	}
-	hash := sha1.Sum([]byte(password))
-	hashStr := strings.ToUpper(hex.EncodeToString(hash[:]))
-	_, exists := d.hashSet[hashStr]
+	_, exists := d.hashSet[hashPassword(password)]
 	return exists, nil
 }
 
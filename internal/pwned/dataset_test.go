@@ -2,6 +2,8 @@ package pwned
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"testing"
 )
 
@@ -47,3 +49,18 @@ func TestNewGlobalDataset(t *testing.T) {
 		t.Fatalf("expected dataset name to be populated")
 	}
 }
+
+func TestDatasetMatchesNormalisedPasswords(t *testing.T) {
+	hash := sha1.Sum([]byte("Gr\u00fc\u00dfe"))
+	dataset, err := NewDataset("test", []string{hex.EncodeToString(hash[:])})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	breached, err := dataset.IsBreached(context.Background(), "Gru\u0308\u00dfe")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !breached {
+		t.Fatal("expected decomposed spelling to match the composed hash")
+	}
+}
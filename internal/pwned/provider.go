@@ -1,9 +1,23 @@
 package pwned
 
-import "context"
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
 
 // Provider represents a breach data source capable of evaluating a password.
 type Provider interface {
 	Name() string
 	IsBreached(ctx context.Context, password string) (bool, error)
 }
+
+// hashPassword returns the upper-case SHA-1 hex digest of the NFKC-normalised password, so
+// equivalent spellings such as composed and decomposed umlauts hash identically.
+func hashPassword(password string) string {
+	hash := sha1.Sum([]byte(norm.NFKC.String(password)))
+	return strings.ToUpper(hex.EncodeToString(hash[:]))
+}
@@ -0,0 +1,377 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EntryType identifies the kind of secret held by a vault entry.
+type EntryType string
+
+const (
+	// EntryTypeLogin is a classic label/password credential.
+	EntryTypeLogin EntryType = "login"
+	// EntryTypeSecureNote is free-form confidential text.
+	EntryTypeSecureNote EntryType = "secure_note"
+	// EntryTypeSSHKey is an SSH private key together with its public key.
+	EntryTypeSSHKey EntryType = "ssh_key"
+	// EntryTypeAPIToken is a bearer token or API key for a service.
+	EntryTypeAPIToken EntryType = "api_token"
+	// EntryTypeRecoveryCodes is a set of single-use account recovery codes.
+	EntryTypeRecoveryCodes EntryType = "recovery_codes"
+)
+
+// EntryTypes lists every supported entry type in display order.
+var EntryTypes = []EntryType{
+	EntryTypeLogin,
+	EntryTypeSecureNote,
+	EntryTypeSSHKey,
+	EntryTypeAPIToken,
+	EntryTypeRecoveryCodes,
+}
+
+// ParseEntryType converts user input such as "ssh-key" into an EntryType.
+func ParseEntryType(value string) (EntryType, error) {
+	normalised := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(value)), "-", "_")
+	switch normalised {
+	case "", "login", "password":
+		return EntryTypeLogin, nil
+	case "note", "secure_note":
+		return EntryTypeSecureNote, nil
+	case "ssh", "ssh_key":
+		return EntryTypeSSHKey, nil
+	case "token", "api_token":
+		return EntryTypeAPIToken, nil
+	case "recovery", "recovery_codes":
+		return EntryTypeRecoveryCodes, nil
+	default:
+		return "", fmt.Errorf("unknown entry type: %s", value)
+	}
+}
+
+// AuditCheck names a check that can be applied to stored entries.
+type AuditCheck string
+
+const (
+	// AuditCheckStrength evaluates the secret against the password policy.
+	AuditCheckStrength AuditCheck = "strength"
+	// AuditCheckBreach looks the secret up in breach datasets.
+	AuditCheckBreach AuditCheck = "breach"
+	// AuditCheckReuse detects the same secret stored under several labels.
+	AuditCheckReuse AuditCheck = "reuse"
+	// AuditCheckRecoveryCodes warns when few unused recovery codes remain.
+	AuditCheckRecoveryCodes AuditCheck = "recovery_codes"
+)
+
+// Supports reports whether the audit check is meaningful for the entry type.
+func (t EntryType) Supports(check AuditCheck) bool {
+	switch t {
+	case EntryTypeLogin:
+		return check == AuditCheckStrength || check == AuditCheckBreach || check == AuditCheckReuse
+	case EntryTypeAPIToken, EntryTypeSSHKey:
+		return check == AuditCheckReuse
+	case EntryTypeRecoveryCodes:
+		return check == AuditCheckRecoveryCodes
+	default:
+		return false
+	}
+}
+
+// SSHKey holds an SSH key pair and its derived fingerprint.
+type SSHKey struct {
+	PrivateKey  string `json:"private_key"`
+	PublicKey   string `json:"public_key"`
+	Algorithm   string `json:"algorithm"`
+	Fingerprint string `json:"fingerprint"`
+	Comment     string `json:"comment,omitempty"`
+}
+
+// APIToken holds an API token and the provider inferred from its prefix.
+type APIToken struct {
+	Token    string `json:"token"`
+	Provider string `json:"provider,omitempty"`
+}
+
+// RecoveryCode is a single-use recovery code and the time it was consumed.
+type RecoveryCode struct {
+	Code   string     `json:"code"`
+	UsedAt *time.Time `json:"used_at,omitempty"`
+}
+
+// Kind returns the entry type, treating entries written before typed entries existed as logins.
+func (p StoredPassword) Kind() EntryType {
+	if p.Type == "" {
+		return EntryTypeLogin
+	}
+	return p.Type
+}
+
+// Secret returns the primary secret value of the entry.
+func (p StoredPassword) Secret() string {
+	switch p.Kind() {
+	case EntryTypeSecureNote:
+		return p.Note
+	case EntryTypeSSHKey:
+		if p.SSHKey == nil {
+			return ""
+		}
+		return p.SSHKey.PrivateKey
+	case EntryTypeAPIToken:
+		if p.APIToken == nil {
+			return ""
+		}
+		return p.APIToken.Token
+	case EntryTypeRecoveryCodes:
+		codes := make([]string, 0, len(p.RecoveryCodes))
+		for _, code := range p.RecoveryCodes {
+			codes = append(codes, code.Code)
+		}
+		return strings.Join(codes, "\n")
+	default:
+		return p.Password
+	}
+}
+
+// RemainingRecoveryCodes returns the number of recovery codes that have not been consumed.
+func (p StoredPassword) RemainingRecoveryCodes() int {
+	remaining := 0
+	for _, code := range p.RecoveryCodes {
+		if code.UsedAt == nil {
+			remaining++
+		}
+	}
+	return remaining
+}
+
+// ConsumedRecoveryCodes returns the number of recovery codes already used.
+func (p StoredPassword) ConsumedRecoveryCodes() int {
+	return len(p.RecoveryCodes) - p.RemainingRecoveryCodes()
+}
+
+// Validate checks that the entry carries exactly the fields required by its type.
+func (p StoredPassword) Validate() error {
+	if strings.TrimSpace(p.Label) == "" {
+		return errors.New("label cannot be empty")
+	}
+
+	switch p.Kind() {
+	case EntryTypeLogin:
+		if p.Password == "" {
+			return errors.New("password cannot be empty")
+		}
+	case EntryTypeSecureNote:
+		if strings.TrimSpace(p.Note) == "" {
+			return errors.New("note cannot be empty")
+		}
+	case EntryTypeSSHKey:
+		if p.SSHKey == nil {
+			return errors.New("ssh key entry requires key material")
+		}
+		if err := validatePrivateKey(p.SSHKey.PrivateKey); err != nil {
+			return err
+		}
+		algorithm, fingerprint, _, err := parseAuthorizedKey(p.SSHKey.PublicKey)
+		if err != nil {
+			return err
+		}
+		if p.SSHKey.Algorithm != algorithm || p.SSHKey.Fingerprint != fingerprint {
+			return errors.New("ssh key fingerprint does not match public key")
+		}
+	case EntryTypeAPIToken:
+		if p.APIToken == nil || strings.TrimSpace(p.APIToken.Token) == "" {
+			return errors.New("api token cannot be empty")
+		}
+		if strings.ContainsAny(p.APIToken.Token, " \t\r\n") {
+			return errors.New("api token must not contain whitespace")
+		}
+	case EntryTypeRecoveryCodes:
+		if len(p.RecoveryCodes) == 0 {
+			return errors.New("at least one recovery code is required")
+		}
+		seen := make(map[string]struct{}, len(p.RecoveryCodes))
+		for i, code := range p.RecoveryCodes {
+			if strings.TrimSpace(code.Code) == "" {
+				return fmt.Errorf("recovery code at index %d is empty", i)
+			}
+			key := normaliseRecoveryCode(code.Code)
+			if _, exists := seen[key]; exists {
+				return fmt.Errorf("duplicate recovery code at index %d", i)
+			}
+			seen[key] = struct{}{}
+		}
+	default:
+		return fmt.Errorf("unknown entry type: %s", p.Type)
+	}
+	return nil
+}
+
+// NewLoginEntry builds a login entry.
+func NewLoginEntry(label, password string) (StoredPassword, error) {
+	entry := StoredPassword{Label: strings.TrimSpace(label), Type: EntryTypeLogin, Password: password}
+	return entry, entry.Validate()
+}
+
+// NewSecureNoteEntry builds a secure note entry.
+func NewSecureNoteEntry(label, note string) (StoredPassword, error) {
+	entry := StoredPassword{Label: strings.TrimSpace(label), Type: EntryTypeSecureNote, Note: note}
+	return entry, entry.Validate()
+}
+
+// NewSSHKeyEntry builds an SSH key entry and derives the SHA256 fingerprint from the public key.
+func NewSSHKeyEntry(label, privateKey, publicKey string) (StoredPassword, error) {
+	if err := validatePrivateKey(privateKey); err != nil {
+		return StoredPassword{}, err
+	}
+	algorithm, fingerprint, comment, err := parseAuthorizedKey(publicKey)
+	if err != nil {
+		return StoredPassword{}, err
+	}
+	entry := StoredPassword{
+		Label: strings.TrimSpace(label),
+		Type:  EntryTypeSSHKey,
+		SSHKey: &SSHKey{
+			PrivateKey:  strings.TrimSpace(privateKey) + "\n",
+			PublicKey:   strings.TrimSpace(publicKey),
+			Algorithm:   algorithm,
+			Fingerprint: fingerprint,
+			Comment:     comment,
+		},
+	}
+	return entry, entry.Validate()
+}
+
+// NewAPITokenEntry builds an API token entry and detects the issuing provider from the token prefix.
+func NewAPITokenEntry(label, token string) (StoredPassword, error) {
+	trimmed := strings.TrimSpace(token)
+	entry := StoredPassword{
+		Label:    strings.TrimSpace(label),
+		Type:     EntryTypeAPIToken,
+		APIToken: &APIToken{Token: trimmed, Provider: DetectTokenProvider(trimmed)},
+	}
+	return entry, entry.Validate()
+}
+
+// NewRecoveryCodesEntry builds a recovery code entry from whitespace- or comma-separated codes.
+func NewRecoveryCodesEntry(label, codes string) (StoredPassword, error) {
+	fields := strings.FieldsFunc(codes, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\r' || r == '\n'
+	})
+	recovery := make([]RecoveryCode, 0, len(fields))
+	for _, field := range fields {
+		recovery = append(recovery, RecoveryCode{Code: field})
+	}
+	entry := StoredPassword{Label: strings.TrimSpace(label), Type: EntryTypeRecoveryCodes, RecoveryCodes: recovery}
+	return entry, entry.Validate()
+}
+
+// NewEntry builds an entry of the given type from its primary secret value.
+// For SSH keys the public key must be supplied separately.
+func NewEntry(entryType EntryType, label, secret, publicKey string) (StoredPassword, error) {
+	switch entryType {
+	case EntryTypeLogin:
+		return NewLoginEntry(label, secret)
+	case EntryTypeSecureNote:
+		return NewSecureNoteEntry(label, secret)
+	case EntryTypeSSHKey:
+		return NewSSHKeyEntry(label, secret, publicKey)
+	case EntryTypeAPIToken:
+		return NewAPITokenEntry(label, secret)
+	case EntryTypeRecoveryCodes:
+		return NewRecoveryCodesEntry(label, secret)
+	default:
+		return StoredPassword{}, fmt.Errorf("unknown entry type: %s", entryType)
+	}
+}
+
+var tokenPrefixes = []struct {
+	prefix   string
+	provider string
+}{
+	{"github_pat_", "GitHub fine-grained personal access token"},
+	{"ghp_", "GitHub personal access token"},
+	{"gho_", "GitHub OAuth token"},
+	{"ghu_", "GitHub user-to-server token"},
+	{"ghs_", "GitHub server-to-server token"},
+	{"ghr_", "GitHub refresh token"},
+	{"glpat-", "GitLab personal access token"},
+	{"xoxb-", "Slack bot token"},
+	{"xoxp-", "Slack user token"},
+	{"xapp-", "Slack app token"},
+	{"sk_live_", "Stripe live secret key"},
+	{"sk_test_", "Stripe test secret key"},
+	{"rk_live_", "Stripe restricted key"},
+	{"sk-ant-", "Anthropic API key"},
+	{"sk-", "OpenAI API key"},
+	{"AKIA", "AWS access key ID"},
+	{"ASIA", "AWS temporary access key ID"},
+	{"AIza", "Google API key"},
+	{"npm_", "npm access token"},
+	{"pypi-", "PyPI API token"},
+	{"dop_v1_", "DigitalOcean personal access token"},
+	{"hf_", "Hugging Face access token"},
+	{"SG.", "SendGrid API key"},
+}
+
+// DetectTokenProvider returns the provider for well-known token prefixes, or an empty string.
+func DetectTokenProvider(token string) string {
+	for _, candidate := range tokenPrefixes {
+		if strings.HasPrefix(token, candidate.prefix) {
+			return candidate.provider
+		}
+	}
+	return ""
+}
+
+func validatePrivateKey(privateKey string) error {
+	block, rest := pem.Decode([]byte(strings.TrimSpace(privateKey)))
+	if block == nil {
+		return errors.New("ssh private key must be PEM encoded")
+	}
+	if !strings.HasSuffix(block.Type, "PRIVATE KEY") {
+		return fmt.Errorf("unexpected PEM block type %q for ssh private key", block.Type)
+	}
+	if len(bytes.TrimSpace(rest)) > 0 {
+		return errors.New("ssh private key contains trailing data")
+	}
+	return nil
+}
+
+// parseAuthorizedKey parses a public key in authorized_keys format and returns its
+// algorithm, OpenSSH SHA256 fingerprint and optional comment.
+func parseAuthorizedKey(publicKey string) (string, string, string, error) {
+	fields := strings.Fields(publicKey)
+	if len(fields) < 2 {
+		return "", "", "", errors.New("ssh public key must be in authorized_keys format")
+	}
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid ssh public key encoding: %w", err)
+	}
+	if len(blob) < 4 {
+		return "", "", "", errors.New("ssh public key is truncated")
+	}
+	nameLength := binary.BigEndian.Uint32(blob[:4])
+	if uint64(nameLength) > uint64(len(blob)-4) {
+		return "", "", "", errors.New("ssh public key is truncated")
+	}
+	algorithm := string(blob[4 : 4+nameLength])
+	if algorithm != fields[0] {
+		return "", "", "", fmt.Errorf("ssh public key algorithm mismatch: %s vs %s", fields[0], algorithm)
+	}
+
+	sum := sha256.Sum256(blob)
+	fingerprint := "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+	comment := strings.Join(fields[2:], " ")
+	return algorithm, fingerprint, comment, nil
+}
+
+func normaliseRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
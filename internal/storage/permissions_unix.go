@@ -0,0 +1,6 @@
+//go:build !windows
+
+package storage
+
+// permissionsEnforced reports whether POSIX permission bits protect the storage files.
+const permissionsEnforced = true
@@ -0,0 +1,6 @@
+//go:build windows
+
+package storage
+
+// permissionsEnforced is false because Windows relies on ACLs rather than POSIX permission bits.
+const permissionsEnforced = false
@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,25 +14,66 @@ import (
 	"time"
 )
 
-// PasswordStore defines persistence operations for stored passwords.
+// ErrNotFound is returned when no entry exists for the requested label.
+var ErrNotFound = errors.New("entry not found")
+
+// ErrLockTimeout is returned when the storage lock is still held after lockAcquireTimeout.
+var ErrLockTimeout = errors.New("timed out waiting for storage lock")
+
+// LockTimeoutError reports that the storage lock could not be acquired before a deadline.
+// It unwraps to ErrLockTimeout or context.DeadlineExceeded, depending on which deadline expired.
+type LockTimeoutError struct {
+	Path   string
+	Waited time.Duration
+	Err    error
+}
+
+func (e *LockTimeoutError) Error() string {
+	return fmt.Sprintf("failed to acquire storage lock %s after %s: %v", e.Path, e.Waited.Round(time.Millisecond), e.Err)
+}
+
+func (e *LockTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// PasswordStore defines persistence operations for stored passwords. All methods honour
+// cancellation and deadlines of the supplied context.
 type PasswordStore interface {
-	Save(label, password string) (StoredPassword, error)
-	List() ([]StoredPassword, error)
+	Save(ctx context.Context, label, password string) (StoredPassword, error)
+	SaveEntry(ctx context.Context, entry StoredPassword) (StoredPassword, error)
+	Get(ctx context.Context, label string) (StoredPassword, error)
+	ConsumeRecoveryCode(ctx context.Context, label, code string) (StoredPassword, error)
+	List(ctx context.Context) ([]StoredPassword, error)
+	Verify(ctx context.Context, repair bool) (VerificationReport, error)
 }
 
-// StoredPassword represents a credential persisted in the store.
+// StoredPassword represents a credential persisted in the store. Only the fields
+// belonging to the entry's Type are populated.
 type StoredPassword struct {
-	Label     string    `json:"label"`
-	Password  string    `json:"password"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	Label         string         `json:"label"`
+	Type          EntryType      `json:"type,omitempty"`
+	Password      string         `json:"password,omitempty"`
+	Note          string         `json:"note,omitempty"`
+	SSHKey        *SSHKey        `json:"ssh_key,omitempty"`
+	APIToken      *APIToken      `json:"api_token,omitempty"`
+	RecoveryCodes []RecoveryCode `json:"recovery_codes,omitempty"`
+	Override      *SaveOverride  `json:"override,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// SaveOverride records why an entry was saved despite failing the save-time gate.
+type SaveOverride struct {
+	Reason     string   `json:"reason"`
+	Violations []string `json:"violations"`
 }
 
 // FileStore persists passwords on disk using a JSON file.
 type FileStore struct {
-	path     string
-	lockPath string
-	mu       sync.Mutex
+	path         string
+	lockPath     string
+	ownDirectory bool
+	mu           sync.Mutex
 }
 
 // NewFileStore initialises a password store that writes to the provided path.
@@ -53,23 +95,35 @@ func NewFileStore(path string) (PasswordStore, error) {
 	return &FileStore{
 		path:     trimmed,
 		lockPath: trimmed + ".lock",
+		// A directory named in the path belongs to the vault; the working directory of a
+		// vault given by file name alone belongs to the user.
+		ownDirectory: directory != ".",
 	}, nil
 }
 
 // Save stores or updates a password under the provided label.
-func (s *FileStore) Save(label, password string) (StoredPassword, error) {
-	cleanLabel := strings.TrimSpace(label)
-	if cleanLabel == "" {
-		return StoredPassword{}, errors.New("label cannot be empty")
+func (s *FileStore) Save(ctx context.Context, label, password string) (StoredPassword, error) {
+	entry, err := NewLoginEntry(label, password)
+	if err != nil {
+		return StoredPassword{}, err
+	}
+	return s.SaveEntry(ctx, entry)
+}
+
+// SaveEntry stores or replaces a typed entry under its label.
+func (s *FileStore) SaveEntry(ctx context.Context, entry StoredPassword) (StoredPassword, error) {
+	entry.Label = strings.TrimSpace(entry.Label)
+	if entry.Type == "" {
+		entry.Type = EntryTypeLogin
 	}
-	if password == "" {
-		return StoredPassword{}, errors.New("password cannot be empty")
+	if err := entry.Validate(); err != nil {
+		return StoredPassword{}, err
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	lock, err := s.acquireFileLock()
+	lock, err := s.acquireFileLock(ctx)
 	if err != nil {
 		return StoredPassword{}, err
 	}
@@ -81,11 +135,11 @@ func (s *FileStore) Save(label, password string) (StoredPassword, error) {
 	}
 
 	now := time.Now().UTC()
+	entry.UpdatedAt = now
 	for idx := range entries {
-		if strings.EqualFold(entries[idx].Label, cleanLabel) {
-			entries[idx].Label = cleanLabel
-			entries[idx].Password = password
-			entries[idx].UpdatedAt = now
+		if strings.EqualFold(entries[idx].Label, entry.Label) {
+			entry.CreatedAt = entries[idx].CreatedAt
+			entries[idx] = entry
 			if err := s.writeAll(entries); err != nil {
 				return StoredPassword{}, err
 			}
@@ -93,22 +147,100 @@ func (s *FileStore) Save(label, password string) (StoredPassword, error) {
 		}
 	}
 
-	record := StoredPassword{
-		Label:     cleanLabel,
-		Password:  password,
-		CreatedAt: now,
-		UpdatedAt: now,
-	}
-	entries = append(entries, record)
+	entry.CreatedAt = now
+	entries = append(entries, entry)
 	if err := s.writeAll(entries); err != nil {
 		return StoredPassword{}, err
 	}
 
-	return record, nil
+	return entry, nil
+}
+
+// Get retrieves the entry stored under the label, matching case-insensitively.
+func (s *FileStore) Get(ctx context.Context, label string) (StoredPassword, error) {
+	cleanLabel := strings.TrimSpace(label)
+	if cleanLabel == "" {
+		return StoredPassword{}, errors.New("label cannot be empty")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return StoredPassword{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.readAll()
+	if err != nil {
+		return StoredPassword{}, err
+	}
+	for _, entry := range entries {
+		if strings.EqualFold(entry.Label, cleanLabel) {
+			return entry, nil
+		}
+	}
+	return StoredPassword{}, fmt.Errorf("%w: %s", ErrNotFound, cleanLabel)
+}
+
+// ConsumeRecoveryCode marks a recovery code of the labelled entry as used.
+func (s *FileStore) ConsumeRecoveryCode(ctx context.Context, label, code string) (StoredPassword, error) {
+	cleanLabel := strings.TrimSpace(label)
+	if cleanLabel == "" {
+		return StoredPassword{}, errors.New("label cannot be empty")
+	}
+	wanted := normaliseRecoveryCode(code)
+	if wanted == "" {
+		return StoredPassword{}, errors.New("recovery code cannot be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lock, err := s.acquireFileLock(ctx)
+	if err != nil {
+		return StoredPassword{}, err
+	}
+	defer s.releaseFileLock(lock)
+
+	entries, err := s.readAll()
+	if err != nil {
+		return StoredPassword{}, err
+	}
+
+	for idx := range entries {
+		if !strings.EqualFold(entries[idx].Label, cleanLabel) {
+			continue
+		}
+		if entries[idx].Kind() != EntryTypeRecoveryCodes {
+			return StoredPassword{}, fmt.Errorf("entry %s does not hold recovery codes", entries[idx].Label)
+		}
+		for codeIdx := range entries[idx].RecoveryCodes {
+			recovery := &entries[idx].RecoveryCodes[codeIdx]
+			if normaliseRecoveryCode(recovery.Code) != wanted {
+				continue
+			}
+			if recovery.UsedAt != nil {
+				return StoredPassword{}, fmt.Errorf("recovery code was already used on %s", recovery.UsedAt.Format(time.RFC1123))
+			}
+			now := time.Now().UTC()
+			recovery.UsedAt = &now
+			entries[idx].UpdatedAt = now
+			if err := s.writeAll(entries); err != nil {
+				return StoredPassword{}, err
+			}
+			return entries[idx], nil
+		}
+		return StoredPassword{}, errors.New("recovery code does not belong to this entry")
+	}
+	return StoredPassword{}, fmt.Errorf("%w: %s", ErrNotFound, cleanLabel)
 }
 
 // List retrieves all stored passwords sorted alphabetically by label.
-func (s *FileStore) List() ([]StoredPassword, error) {
+func (s *FileStore) List(ctx context.Context) ([]StoredPassword, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -177,10 +309,25 @@ const (
 	lockStaleAgeThreshold = 30 * time.Second
 )
 
-func (s *FileStore) acquireFileLock() (*os.File, error) {
-	deadline := time.Now().Add(lockAcquireTimeout)
+// acquireFileLock creates the lock file, retrying until lockAcquireTimeout elapses or
+// the context is cancelled or reaches its deadline, whichever comes first.
+func (s *FileStore) acquireFileLock(ctx context.Context) (*os.File, error) {
+	started := time.Now()
+	deadline := started.Add(lockAcquireTimeout)
+	deadlineErr := ErrLockTimeout
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+		deadlineErr = context.DeadlineExceeded
+	}
 
 	for {
+		if err := ctx.Err(); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return nil, &LockTimeoutError{Path: s.lockPath, Waited: time.Since(started), Err: err}
+			}
+			return nil, fmt.Errorf("failed to acquire storage lock: %w", err)
+		}
+
 		lockFile, err := os.OpenFile(s.lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
 		if err == nil {
 			// Record when the lock was acquired to help with stale detection if the process dies unexpectedly.
@@ -209,10 +356,19 @@ func (s *FileStore) acquireFileLock() (*os.File, error) {
 					}
 				}
 			}
-			if time.Now().After(deadline) {
-				return nil, fmt.Errorf("failed to acquire storage lock: timed out waiting for lock")
+			if !time.Now().Before(deadline) {
+				return nil, &LockTimeoutError{Path: s.lockPath, Waited: time.Since(started), Err: deadlineErr}
+			}
+			wait := lockRetryInterval
+			if remaining := time.Until(deadline); remaining < wait {
+				wait = remaining
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+			case <-timer.C:
 			}
-			time.Sleep(lockRetryInterval)
 			continue
 		}
 		return nil, fmt.Errorf("failed to acquire storage lock: %w", err)
@@ -0,0 +1,230 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	filePermissions      os.FileMode = 0o600
+	directoryPermissions os.FileMode = 0o700
+	// clockSkewTolerance is how far in the future a timestamp may lie before it is reported.
+	clockSkewTolerance = 5 * time.Minute
+)
+
+// VerificationIssue describes a single integrity problem found by Verify.
+type VerificationIssue struct {
+	Code     string
+	Message  string
+	Path     string
+	Repaired bool
+}
+
+// VerificationReport summarises the result of an integrity check.
+type VerificationReport struct {
+	Path    string
+	Entries int
+	Issues  []VerificationIssue
+}
+
+// Healthy reports whether no unrepaired issues remain.
+func (r VerificationReport) Healthy() bool {
+	for _, issue := range r.Issues {
+		if !issue.Repaired {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *VerificationReport) add(code, path, message string, repaired bool) {
+	r.Issues = append(r.Issues, VerificationIssue{Code: code, Message: message, Path: path, Repaired: repaired})
+}
+
+// Verify validates the storage file, its permissions and leftover artefacts from
+// interrupted writes. When repair is true, issues that can be fixed without data
+// loss (permissions, stale locks, temporary files, inconsistent timestamps) are fixed. The
+// permissions of the directory are only repaired when the store path names it, never for the
+// working directory holding a vault given by file name alone.
+func (s *FileStore) Verify(ctx context.Context, repair bool) (VerificationReport, error) {
+	report := VerificationReport{Path: s.path}
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.verifyLockFile(&report, repair)
+
+	if repair {
+		lock, err := s.acquireFileLock(ctx)
+		if err != nil {
+			return report, err
+		}
+		defer s.releaseFileLock(lock)
+	}
+
+	s.verifyTemporaryFiles(&report, repair)
+	// Only the vault's own directory is tightened; any other is reported but left alone.
+	verifyPermissions(&report, filepath.Dir(s.path), directoryPermissions, "permissions.directory", repair && s.ownDirectory)
+	verifyPermissions(&report, s.path, filePermissions, "permissions.file", repair)
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		report.add("file.unreadable", s.path, fmt.Sprintf("storage file cannot be read: %v", err), false)
+		return report, nil
+	}
+
+	entries, err := decodeStrict(data)
+	if err != nil {
+		report.add("schema.invalid", s.path, err.Error(), false)
+		return report, nil
+	}
+	report.Entries = len(entries)
+
+	changed := verifyEntries(&report, s.path, entries, repair)
+	if changed {
+		if err := s.writeAll(entries); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+func (s *FileStore) verifyLockFile(report *VerificationReport, repair bool) {
+	if _, err := os.Stat(s.lockPath); err != nil {
+		return
+	}
+	stale, err := isLockFileStale(s.lockPath)
+	if err != nil || !stale {
+		return
+	}
+	repaired := repair && os.Remove(s.lockPath) == nil
+	report.add("lock.stale", s.lockPath, "lock file belongs to a process that is no longer running", repaired)
+}
+
+func (s *FileStore) verifyTemporaryFiles(report *VerificationReport, repair bool) {
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(s.path), "password-store-*.tmp"))
+	if err != nil {
+		return
+	}
+	for _, match := range matches {
+		// In repair mode the store lock is held, so no concurrent writer can own these files.
+		repaired := repair && os.Remove(match) == nil
+		report.add("file.temporary", match, "temporary file left behind by an interrupted write", repaired)
+	}
+}
+
+func verifyPermissions(report *VerificationReport, path string, want os.FileMode, code string, repair bool) {
+	if !permissionsEnforced {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	if got := info.Mode().Perm(); got != want {
+		repaired := repair && os.Chmod(path, want) == nil
+		report.add(code, path, fmt.Sprintf("permissions are %04o, expected %04o", got, want), repaired)
+	}
+}
+
+// decodeStrict decodes the storage payload, rejecting unknown fields and reporting
+// the line on which decoding failed.
+func decodeStrict(data []byte) ([]StoredPassword, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, errors.New("storage file is empty")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var payload struct {
+		Entries *[]StoredPassword `json:"entries"`
+	}
+	if err := decoder.Decode(&payload); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			return nil, fmt.Errorf("line %d: %v", lineAt(data, syntaxErr.Offset), err)
+		case errors.As(err, &typeErr):
+			return nil, fmt.Errorf("line %d: field %s: %v", lineAt(data, typeErr.Offset), typeErr.Field, err)
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return nil, fmt.Errorf("storage file is truncated: %v", err)
+		default:
+			return nil, err
+		}
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected data after storage payload")
+	}
+	if payload.Entries == nil {
+		return nil, errors.New(`missing "entries" array`)
+	}
+	return *payload.Entries, nil
+}
+
+func verifyEntries(report *VerificationReport, path string, entries []StoredPassword, repair bool) bool {
+	changed := false
+	now := time.Now().UTC()
+	labels := make(map[string]string, len(entries))
+
+	for idx := range entries {
+		entry := &entries[idx]
+		ref := fmt.Sprintf("%s#entries[%d]", path, idx)
+
+		if err := entry.Validate(); err != nil {
+			report.add("entry.invalid", ref, err.Error(), false)
+		}
+
+		key := strings.ToLower(strings.TrimSpace(entry.Label))
+		if previous, exists := labels[key]; exists && key != "" {
+			report.add("label.duplicate", ref, fmt.Sprintf("label %q duplicates %q (labels are case-insensitive)", entry.Label, previous), false)
+		} else {
+			labels[key] = entry.Label
+		}
+
+		if entry.CreatedAt.IsZero() || entry.UpdatedAt.IsZero() {
+			repaired := false
+			if repair {
+				switch {
+				case entry.CreatedAt.IsZero() && entry.UpdatedAt.IsZero():
+					entry.CreatedAt, entry.UpdatedAt = now, now
+				case entry.CreatedAt.IsZero():
+					entry.CreatedAt = entry.UpdatedAt
+				default:
+					entry.UpdatedAt = entry.CreatedAt
+				}
+				repaired, changed = true, true
+			}
+			report.add("timestamp.missing", ref, "created_at or updated_at is missing", repaired)
+		} else if entry.UpdatedAt.Before(entry.CreatedAt) {
+			repaired := false
+			if repair {
+				entry.UpdatedAt = entry.CreatedAt
+				repaired, changed = true, true
+			}
+			report.add("timestamp.order", ref, "updated_at lies before created_at", repaired)
+		}
+		if entry.CreatedAt.After(now.Add(clockSkewTolerance)) || entry.UpdatedAt.After(now.Add(clockSkewTolerance)) {
+			report.add("timestamp.future", ref, "timestamp lies in the future", false)
+		}
+	}
+	return changed
+}
+
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
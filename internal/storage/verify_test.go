@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func hasIssue(report VerificationReport, code string) (VerificationIssue, bool) {
+	for _, issue := range report.Issues {
+		if issue.Code == code {
+			return issue, true
+		}
+	}
+	return VerificationIssue{}, false
+}
+
+func TestVerifyReportsTruncatedFileWithLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "passwords.json")
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("{\n  \"entries\": [\n    {\"label\": 5}\n  ]\n}\n"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	report, err := store.Verify(context.Background(), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	issue, ok := hasIssue(report, "schema.invalid")
+	if !ok || !strings.Contains(issue.Message, "line 3") {
+		t.Fatalf("expected schema issue on line 3, got %+v", report.Issues)
+	}
+	if report.Healthy() {
+		t.Fatalf("expected report to be unhealthy")
+	}
+}
+
+func TestVerifyDetectsDuplicateLabels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "passwords.json")
+	payload := `{"entries":[
+{"label":"Mail","password":"a","created_at":"2024-01-01T00:00:00Z","updated_at":"2024-01-01T00:00:00Z"},
+{"label":"mail","password":"b","created_at":"2024-01-01T00:00:00Z","updated_at":"2024-01-01T00:00:00Z"}]}`
+	if err := os.WriteFile(path, []byte(payload), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	report, err := store.Verify(context.Background(), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue, ok := hasIssue(report, "label.duplicate"); !ok || issue.Repaired {
+		t.Fatalf("expected unrepaired duplicate label issue, got %+v", report.Issues)
+	}
+}
+
+func TestVerifyRepairsSafeIssues(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "passwords.json")
+	payload := `{"entries":[{"label":"mail","password":"a","created_at":"2024-02-01T00:00:00Z","updated_at":"2024-01-01T00:00:00Z"}]}`
+	if err := os.WriteFile(path, []byte(payload), 0o644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	leftover := filepath.Join(dir, "password-store-123.tmp")
+	if err := os.WriteFile(leftover, []byte("{}"), 0o600); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store, err := NewFileStore(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	report, err := store.Verify(context.Background(), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, code := range []string{"timestamp.order", "file.temporary"} {
+		if issue, ok := hasIssue(report, code); !ok || !issue.Repaired {
+			t.Fatalf("expected repaired %s issue, got %+v", code, report.Issues)
+		}
+	}
+	if permissionsEnforced {
+		if issue, ok := hasIssue(report, "permissions.file"); !ok || !issue.Repaired {
+			t.Fatalf("expected repaired permission issue, got %+v", report.Issues)
+		}
+	}
+	if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+		t.Fatalf("expected temporary file to be removed")
+	}
+
+	report, err = store.Verify(context.Background(), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Issues) != 0 {
+		t.Fatalf("expected clean report after repair, got %+v", report.Issues)
+	}
+}
+
+func TestVerifyLeavesTheWorkingDirectoryAlone(t *testing.T) {
+	if !permissionsEnforced {
+		t.Skip("permissions are not enforced on this platform")
+	}
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0o755); err != nil {
+		t.Fatalf("chmod: %v", err)
+	}
+	previous, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(previous) })
+
+	store, err := NewFileStore("passwords.json")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report, err := store.Verify(context.Background(), true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue, ok := hasIssue(report, "permissions.directory"); !ok || issue.Repaired {
+		t.Fatalf("expected the directory to be reported but not repaired, got %+v", report.Issues)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if got := info.Mode().Perm(); got != 0o755 {
+		t.Fatalf("expected the working directory to keep its permissions, got %04o", got)
+	}
+
+	named, err := NewFileStore(filepath.Join(dir, "passwords.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report, err = named.Verify(context.Background(), true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if issue, ok := hasIssue(report, "permissions.directory"); !ok || !issue.Repaired {
+		t.Fatalf("expected a named directory to be repaired, got %+v", report.Issues)
+	}
+}
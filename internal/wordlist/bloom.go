@@ -0,0 +1,62 @@
+package wordlist
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// falsePositiveRate is the target probability that Contains reports a word that is not listed.
+const falsePositiveRate = 0.001
+
+// bloomFilter is a fixed-size Bloom filter using double hashing to derive its k probes.
+type bloomFilter struct {
+	bits   []uint64
+	m      uint64
+	k      uint32
+	number uint64
+}
+
+func newBloomFilter(expected uint64) *bloomFilter {
+	if expected == 0 {
+		expected = 1
+	}
+	m := uint64(math.Ceil(-float64(expected) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	k := uint32(math.Round(float64(m) / float64(expected) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &bloomFilter{bits: make([]uint64, (m+63)/64), m: m, k: k}
+}
+
+func (f *bloomFilter) add(word string) {
+	h1, h2 := bloomHashes(word)
+	for i := uint32(0); i < f.k; i++ {
+		bit := (h1 + uint64(i)*h2) % f.m
+		f.bits[bit/64] |= 1 << (bit % 64)
+	}
+	f.number++
+}
+
+func (f *bloomFilter) contains(word string) bool {
+	h1, h2 := bloomHashes(word)
+	for i := uint32(0); i < f.k; i++ {
+		bit := (h1 + uint64(i)*h2) % f.m
+		if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomHashes returns two independent 64-bit hashes; the second is forced odd so the
+// probe sequence never collapses onto a single bit.
+func bloomHashes(word string) (uint64, uint64) {
+	first := fnv.New64a()
+	first.Write([]byte(word))
+	second := fnv.New64()
+	second.Write([]byte(word))
+	return first.Sum64(), second.Sum64() | 1
+}
@@ -0,0 +1,285 @@
+// Package wordlist indexes large banned-password lists into Bloom filters that are
+// cached on disk, so multi-million line lists load in milliseconds after the first run.
+package wordlist
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// cacheMagic changes whenever entries are normalised differently or the header changes,
+	// so old caches are rebuilt.
+	cacheMagic       = "PCBLOOM3"
+	cachePermissions = 0o600
+	cacheDirectory   = 0o700
+	// headerSize covers magic, source size, modification time and checksum, m, k and the
+	// number of entries.
+	headerSize = len(cacheMagic) + 8 + 8 + sha256.Size + 8 + 4 + 8
+)
+
+// source identifies the contents of a list file. Size and modification time decide whether
+// the cached index is still current; the checksum is only computed when either changed.
+type source struct {
+	size    int64
+	modTime int64
+	sum     [sha256.Size]byte
+}
+
+// List is a banned-password list backed by a Bloom filter. Lookups never miss a listed
+// password but may, with a probability of about 0.1%, report an unlisted one.
+type List struct {
+	name   string
+	filter *bloomFilter
+}
+
+// Name identifies the list, derived from its file name without extensions.
+func (l *List) Name() string {
+	return l.name
+}
+
+// Len returns the number of entries indexed from the source list.
+func (l *List) Len() uint64 {
+	return l.filter.number
+}
+
+// Contains reports whether the password is (probably) on the list. Matching is case-insensitive.
+func (l *List) Contains(password string) bool {
+	return l.filter.contains(normalise(password))
+}
+
+// Load indexes the plain-text or gzip-compressed list at path, one password per line.
+// The index is cached in cacheDir under the source path, together with the size,
+// modification time and SHA-256 checksum of the source. While size and modification time
+// are unchanged the cache is used without reading the source; otherwise the source is
+// hashed and the index only rebuilt if its contents changed. A cache file is only used if
+// it passes its own checksum.
+func Load(path, cacheDir string) (*List, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, errors.New("wordlist path cannot be empty")
+	}
+	name := listName(path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("read wordlist %s: %w", path, err)
+	}
+	current := source{size: info.Size(), modTime: info.ModTime().UnixNano()}
+
+	prefix := cachePrefix(path, name)
+	cachePath := filepath.Join(cacheDir, strings.TrimSuffix(prefix, "-")+".bloom")
+	var cached *bloomFilter
+	var cachedSource source
+	if cacheDir != "" {
+		if cached, cachedSource, err = readCache(cachePath); err == nil && cachedSource.size == current.size && cachedSource.modTime == current.modTime {
+			return &List{name: name, filter: cached}, nil
+		}
+	}
+
+	if current.sum, err = fileChecksum(path); err != nil {
+		return nil, fmt.Errorf("read wordlist %s: %w", path, err)
+	}
+	filter := cached
+	if filter == nil || cachedSource.sum != current.sum {
+		if filter, err = build(path); err != nil {
+			return nil, fmt.Errorf("index wordlist %s: %w", path, err)
+		}
+	}
+	if cacheDir != "" {
+		removeStaleCaches(cacheDir, prefix)
+		if err := writeCache(cachePath, current, filter); err != nil {
+			return nil, fmt.Errorf("cache wordlist %s: %w", path, err)
+		}
+	}
+	return &List{name: name, filter: filter}, nil
+}
+
+func listName(path string) string {
+	name := filepath.Base(path)
+	name = strings.TrimSuffix(name, ".gz")
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// cachePrefix names the caches of one source file: lists with the same file name in
+// different directories get different prefixes and never replace each other's cache.
+func cachePrefix(path, name string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	pathSum := sha256.Sum256([]byte(path))
+	return fmt.Sprintf("%s-%s-", name, hex.EncodeToString(pathSum[:4]))
+}
+
+// normalise applies the NFKC normalisation the evaluator applies to passwords, so full-width
+// and other compatibility forms in a list match, and lowercases the word.
+func normalise(word string) string {
+	return strings.ToLower(strings.TrimSpace(norm.NFKC.String(word)))
+}
+
+func fileChecksum(path string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	file, err := os.Open(path)
+	if err != nil {
+		return sum, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return sum, err
+	}
+	copy(sum[:], hash.Sum(nil))
+	return sum, nil
+}
+
+// build reads the source twice: once to size the filter and once to fill it.
+func build(path string) (*bloomFilter, error) {
+	var count uint64
+	if err := eachWord(path, func(string) { count++ }); err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, errors.New("wordlist contains no entries")
+	}
+	filter := newBloomFilter(count)
+	if err := eachWord(path, filter.add); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+func eachWord(path string, fn func(string)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var source io.Reader = reader
+	if magic, err := reader.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(reader)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		source = gz
+	}
+
+	scanner := bufio.NewScanner(source)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if word := normalise(scanner.Text()); word != "" {
+			fn(word)
+		}
+	}
+	return scanner.Err()
+}
+
+// readCache returns the cached filter and the source it was built from.
+func readCache(path string) (*bloomFilter, source, error) {
+	var src source
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, src, err
+	}
+	if len(data) < headerSize+sha256.Size {
+		return nil, src, errors.New("cache file is truncated")
+	}
+	body, trailer := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	if sum := sha256.Sum256(body); !bytes.Equal(sum[:], trailer) {
+		return nil, src, errors.New("cache checksum mismatch")
+	}
+	if string(body[:len(cacheMagic)]) != cacheMagic {
+		return nil, src, errors.New("unknown cache format")
+	}
+	offset := len(cacheMagic)
+	src.size = int64(binary.LittleEndian.Uint64(body[offset:]))
+	src.modTime = int64(binary.LittleEndian.Uint64(body[offset+8:]))
+	offset += 16
+	copy(src.sum[:], body[offset:offset+sha256.Size])
+	offset += sha256.Size
+
+	filter := &bloomFilter{
+		m:      binary.LittleEndian.Uint64(body[offset:]),
+		k:      binary.LittleEndian.Uint32(body[offset+8:]),
+		number: binary.LittleEndian.Uint64(body[offset+12:]),
+	}
+	words := body[headerSize:]
+	if filter.m == 0 || filter.k == 0 || uint64(len(words)) != (filter.m+63)/64*8 {
+		return nil, src, errors.New("cache header does not match its payload")
+	}
+	filter.bits = make([]uint64, len(words)/8)
+	for idx := range filter.bits {
+		filter.bits[idx] = binary.LittleEndian.Uint64(words[idx*8:])
+	}
+	return filter, src, nil
+}
+
+// removeStaleCaches deletes indexes of the same source file in the earlier format, which
+// named each version of the source by its checksum.
+func removeStaleCaches(cacheDir, prefix string) {
+	matches, err := filepath.Glob(filepath.Join(cacheDir, prefix+"*.bloom"))
+	if err != nil {
+		return
+	}
+	for _, match := range matches {
+		_ = os.Remove(match)
+	}
+}
+
+// writeCache stores the filter atomically so a concurrent reader never sees a partial file.
+func writeCache(path string, src source, filter *bloomFilter) error {
+	if err := os.MkdirAll(filepath.Dir(path), cacheDirectory); err != nil {
+		return err
+	}
+
+	body := make([]byte, headerSize, headerSize+len(filter.bits)*8+sha256.Size)
+	copy(body, cacheMagic)
+	offset := len(cacheMagic)
+	binary.LittleEndian.PutUint64(body[offset:], uint64(src.size))
+	binary.LittleEndian.PutUint64(body[offset+8:], uint64(src.modTime))
+	offset += 16
+	copy(body[offset:], src.sum[:])
+	offset += sha256.Size
+	binary.LittleEndian.PutUint64(body[offset:], filter.m)
+	binary.LittleEndian.PutUint32(body[offset+8:], filter.k)
+	binary.LittleEndian.PutUint64(body[offset+12:], filter.number)
+	for _, word := range filter.bits {
+		body = binary.LittleEndian.AppendUint64(body, word)
+	}
+	sum := sha256.Sum256(body)
+	body = append(body, sum[:]...)
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "wordlist-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := tmp.Write(body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(cachePermissions); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpName, path)
+}
@@ -0,0 +1,211 @@
+package wordlist
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeList(t *testing.T, path string, words []string, compress bool) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create list: %v", err)
+	}
+	defer file.Close()
+
+	if !compress {
+		for _, word := range words {
+			fmt.Fprintln(file, word)
+		}
+		return
+	}
+	gz := gzip.NewWriter(file)
+	for _, word := range words {
+		fmt.Fprintln(gz, word)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("close gzip: %v", err)
+	}
+}
+
+func TestLoadPlainAndGzipLists(t *testing.T) {
+	dir := t.TempDir()
+	words := make([]string, 0, 5000)
+	for i := 0; i < 5000; i++ {
+		words = append(words, fmt.Sprintf("Banned%04d", i))
+	}
+
+	for _, compress := range []bool{false, true} {
+		path := filepath.Join(dir, "corporate.txt")
+		if compress {
+			path += ".gz"
+		}
+		writeList(t, path, words, compress)
+
+		list, err := Load(path, filepath.Join(dir, "cache"))
+		if err != nil {
+			t.Fatalf("load list: %v", err)
+		}
+		if list.Name() != "corporate" || list.Len() != 5000 {
+			t.Fatalf("unexpected list metadata: %s %d", list.Name(), list.Len())
+		}
+		for _, word := range words {
+			if !list.Contains(word) {
+				t.Fatalf("expected %s to be listed", word)
+			}
+		}
+		falsePositives := 0
+		for i := 0; i < 10000; i++ {
+			if list.Contains(fmt.Sprintf("unlisted-%d", i)) {
+				falsePositives++
+			}
+		}
+		if falsePositives > 50 {
+			t.Fatalf("false positive rate too high: %d of 10000", falsePositives)
+		}
+	}
+}
+
+func TestLoadReusesAndInvalidatesCache(t *testing.T) {
+	dir := t.TempDir()
+	cacheDir := filepath.Join(dir, "cache")
+	path := filepath.Join(dir, "banned.txt")
+	writeList(t, path, []string{"hunter2", "correcthorse"}, false)
+
+	if _, err := Load(path, cacheDir); err != nil {
+		t.Fatalf("first load: %v", err)
+	}
+	caches, _ := filepath.Glob(filepath.Join(cacheDir, "banned-*.bloom"))
+	if len(caches) != 1 {
+		t.Fatalf("expected one cache file, got %v", caches)
+	}
+
+	list, err := Load(path, cacheDir)
+	if err != nil || !list.Contains("HUNTER2") {
+		t.Fatalf("cached load failed: %v", err)
+	}
+
+	writeList(t, path, []string{"sommer2024"}, false)
+	list, err = Load(path, cacheDir)
+	if err != nil {
+		t.Fatalf("reload after change: %v", err)
+	}
+	if !list.Contains("sommer2024") {
+		t.Fatal("expected rebuilt index to contain the new entry")
+	}
+	caches, _ = filepath.Glob(filepath.Join(cacheDir, "banned-*.bloom"))
+	if len(caches) != 1 {
+		t.Fatalf("expected stale cache to be replaced, got %v", caches)
+	}
+}
+
+func TestLoadTrustsCacheWhileSizeAndTimeMatch(t *testing.T) {
+	dir := t.TempDir()
+	cacheDir := filepath.Join(dir, "cache")
+	path := filepath.Join(dir, "banned.txt")
+	writeList(t, path, []string{"hunter2"}, false)
+	if _, err := Load(path, cacheDir); err != nil {
+		t.Fatalf("first load: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+
+	// Same size and modification time: the cache is used without reading the source.
+	writeList(t, path, []string{"sommer2"}, false)
+	if err := os.Chtimes(path, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	list, err := Load(path, cacheDir)
+	if err != nil || !list.Contains("hunter2") || list.Contains("sommer2") {
+		t.Fatalf("expected the cached index to be used, got %v", err)
+	}
+
+	modified := info.ModTime().Add(time.Minute)
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	if list, err = Load(path, cacheDir); err != nil || !list.Contains("sommer2") {
+		t.Fatalf("expected a changed modification time to rebuild the index, got %v", err)
+	}
+}
+
+func TestLoadKeepsCachesOfListsWithTheSameName(t *testing.T) {
+	dir := t.TempDir()
+	cacheDir := filepath.Join(dir, "cache")
+	first := filepath.Join(dir, "team-a", "banned.txt")
+	second := filepath.Join(dir, "team-b", "banned.txt")
+	for _, path := range []string{first, second} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatalf("create directory: %v", err)
+		}
+	}
+	writeList(t, first, []string{"hunter2"}, false)
+	writeList(t, second, []string{"sommer2024"}, false)
+
+	for i := 0; i < 2; i++ {
+		for _, path := range []string{first, second} {
+			if _, err := Load(path, cacheDir); err != nil {
+				t.Fatalf("load %s: %v", path, err)
+			}
+		}
+	}
+	caches, _ := filepath.Glob(filepath.Join(cacheDir, "banned-*.bloom"))
+	if len(caches) != 2 {
+		t.Fatalf("expected both caches to survive, got %v", caches)
+	}
+}
+
+func TestLoadNormalisesEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "banned.txt")
+	// Full-width letters and digits, as typed with some East Asian input methods.
+	writeList(t, path, []string{"ＨＵＮＴＥＲ２"}, false)
+	list, err := Load(path, "")
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if !list.Contains("hunter2") || !list.Contains("Ｈｕｎｔｅｒ２") {
+		t.Fatal("expected compatibility forms to match their normalised password")
+	}
+}
+
+func TestLoadRebuildsCorruptedCache(t *testing.T) {
+	dir := t.TempDir()
+	cacheDir := filepath.Join(dir, "cache")
+	path := filepath.Join(dir, "banned.txt")
+	writeList(t, path, []string{"hunter2"}, false)
+
+	if _, err := Load(path, cacheDir); err != nil {
+		t.Fatalf("first load: %v", err)
+	}
+	caches, _ := filepath.Glob(filepath.Join(cacheDir, "banned-*.bloom"))
+	data, err := os.ReadFile(caches[0])
+	if err != nil {
+		t.Fatalf("read cache: %v", err)
+	}
+	data[len(data)/2] ^= 0xff
+	if err := os.WriteFile(caches[0], data, 0o600); err != nil {
+		t.Fatalf("corrupt cache: %v", err)
+	}
+
+	if _, _, err := readCache(caches[0]); err == nil {
+		t.Fatal("expected corrupted cache to be rejected")
+	}
+	list, err := Load(path, cacheDir)
+	if err != nil || !list.Contains("hunter2") {
+		t.Fatalf("expected rebuild after corruption: %v", err)
+	}
+}
+
+func TestLoadRejectsEmptyList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.txt")
+	writeList(t, path, nil, false)
+	if _, err := Load(path, ""); err == nil {
+		t.Fatal("expected error for empty list")
+	}
+}